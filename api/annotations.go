@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/effective-security/x/slices"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 )
 
@@ -137,14 +139,41 @@ func (m *FieldMeta) IsPrintable() bool {
 	return !skipPrintableTypes[m.Type]
 }
 
-// FilterPrintableFields filters the fields by the names
+// FilterPrintableFields filters the fields by the names,
+// INPUT_ONLY fields are not printable
 func FilterPrintableFields(fields []*FieldMeta) []*FieldMeta {
 	var res []*FieldMeta
 	for _, field := range fields {
-		if skipPrintableTypes[field.Type] /*|| field.Type[0] == '[' */ {
+		if skipPrintableTypes[field.Type] || field.InputOnly /*|| field.Type[0] == '[' */ {
 			continue
 		}
 		res = append(res, field)
 	}
 	return res
 }
+
+// filterOutputFields returns the fields without INPUT_ONLY fields
+func filterOutputFields(fields []*FieldMeta) []*FieldMeta {
+	var res []*FieldMeta
+	for _, field := range fields {
+		if !field.InputOnly {
+			res = append(res, field)
+		}
+	}
+	return res
+}
+
+// HasFieldBehavior returns true, if the field has google.api.field_behavior
+func HasFieldBehavior(fd protoreflect.FieldDescriptor, behavior annotations.FieldBehavior) bool {
+	opts := fd.Options()
+	if opts == nil || !proto.HasExtension(opts, annotations.E_FieldBehavior) {
+		return false
+	}
+	behaviors, _ := proto.GetExtension(opts, annotations.E_FieldBehavior).([]annotations.FieldBehavior)
+	for _, b := range behaviors {
+		if b == behavior {
+			return true
+		}
+	}
+	return false
+}
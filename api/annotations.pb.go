@@ -99,7 +99,73 @@ func (x SearchOption_Enum) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use SearchOption_Enum.Descriptor instead.
 func (SearchOption_Enum) EnumDescriptor() ([]byte, []int) {
-	return file_annotations_proto_rawDescGZIP(), []int{1, 0}
+	return file_annotations_proto_rawDescGZIP(), []int{2, 0}
+}
+
+// MessageRule is the message-level validation rule.
+type MessageRule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID is the optional identifier of the rule, reported as the violation
+	// rule, by default "expr".
+	ID string `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	// Expr is the CEL expression, which must return bool. The message is
+	// available as `this`, for example: size(this.IDs) <= this.Limit
+	Expr string `protobuf:"bytes,2,opt,name=Expr,proto3" json:"Expr,omitempty"`
+	// Message is the error message, if the expression returns false.
+	Message       string `protobuf:"bytes,3,opt,name=Message,proto3" json:"Message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageRule) Reset() {
+	*x = MessageRule{}
+	mi := &file_annotations_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageRule) ProtoMessage() {}
+
+func (x *MessageRule) ProtoReflect() protoreflect.Message {
+	mi := &file_annotations_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageRule.ProtoReflect.Descriptor instead.
+func (*MessageRule) Descriptor() ([]byte, []int) {
+	return file_annotations_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MessageRule) GetID() string {
+	if x != nil {
+		return x.ID
+	}
+	return ""
+}
+
+func (x *MessageRule) GetExpr() string {
+	if x != nil {
+		return x.Expr
+	}
+	return ""
+}
+
+func (x *MessageRule) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
 }
 
 type EnumMeta struct {
@@ -118,7 +184,7 @@ type EnumMeta struct {
 
 func (x *EnumMeta) Reset() {
 	*x = EnumMeta{}
-	mi := &file_annotations_proto_msgTypes[0]
+	mi := &file_annotations_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -130,7 +196,7 @@ func (x *EnumMeta) String() string {
 func (*EnumMeta) ProtoMessage() {}
 
 func (x *EnumMeta) ProtoReflect() protoreflect.Message {
-	mi := &file_annotations_proto_msgTypes[0]
+	mi := &file_annotations_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -143,7 +209,7 @@ func (x *EnumMeta) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnumMeta.ProtoReflect.Descriptor instead.
 func (*EnumMeta) Descriptor() ([]byte, []int) {
-	return file_annotations_proto_rawDescGZIP(), []int{0}
+	return file_annotations_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *EnumMeta) GetValue() int32 {
@@ -210,7 +276,7 @@ type SearchOption struct {
 
 func (x *SearchOption) Reset() {
 	*x = SearchOption{}
-	mi := &file_annotations_proto_msgTypes[1]
+	mi := &file_annotations_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -222,7 +288,7 @@ func (x *SearchOption) String() string {
 func (*SearchOption) ProtoMessage() {}
 
 func (x *SearchOption) ProtoReflect() protoreflect.Message {
-	mi := &file_annotations_proto_msgTypes[1]
+	mi := &file_annotations_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -235,7 +301,7 @@ func (x *SearchOption) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SearchOption.ProtoReflect.Descriptor instead.
 func (*SearchOption) Descriptor() ([]byte, []int) {
-	return file_annotations_proto_rawDescGZIP(), []int{1}
+	return file_annotations_proto_rawDescGZIP(), []int{2}
 }
 
 type FieldMeta struct {
@@ -276,14 +342,52 @@ type FieldMeta struct {
 	// Deprecated is the option for the field to be deprecated.
 	Deprecated bool `protobuf:"varint,18,opt,name=Deprecated,proto3" json:"Deprecated,omitempty"`
 	// Alias is the option for the field alias name for the search query.
-	Alias         string `protobuf:"bytes,19,opt,name=Alias,proto3" json:"Alias,omitempty"`
+	Alias string `protobuf:"bytes,19,opt,name=Alias,proto3" json:"Alias,omitempty"`
+	// Pattern is the option for the field to match the regular expression.
+	Pattern string `protobuf:"bytes,20,opt,name=Pattern,proto3" json:"Pattern,omitempty"`
+	// Prefix is the option for the field to start with the value.
+	Prefix string `protobuf:"bytes,21,opt,name=Prefix,proto3" json:"Prefix,omitempty"`
+	// Suffix is the option for the field to end with the value.
+	Suffix string `protobuf:"bytes,22,opt,name=Suffix,proto3" json:"Suffix,omitempty"`
+	// Format is the option for the field to be in a well-known format:
+	// email|uri|hostname|ip|ipv4|ipv6|uuid|ulid.
+	Format string `protobuf:"bytes,23,opt,name=Format,proto3" json:"Format,omitempty"`
+	// In is the option for the field value to be one of the listed values.
+	In []string `protobuf:"bytes,24,rep,name=In,proto3" json:"In,omitempty"`
+	// NotIn is the option for the field value to not be one of the listed
+	// values.
+	NotIn []string `protobuf:"bytes,25,rep,name=NotIn,proto3" json:"NotIn,omitempty"`
+	// Range is the option for the field value range of numbers.
+	Range *NumberRange `protobuf:"bytes,26,opt,name=Range,proto3" json:"Range,omitempty"`
+	// TimeRange is the option for the Timestamp and Duration field bounds.
+	TimeRange *TimeRange `protobuf:"bytes,27,opt,name=TimeRange,proto3" json:"TimeRange,omitempty"`
+	// Unique is the option for the items of repeated fields to be unique.
+	Unique bool `protobuf:"varint,28,opt,name=Unique,proto3" json:"Unique,omitempty"`
+	// Items is the option for the item constraints of repeated fields.
+	Items *ItemRules `protobuf:"bytes,29,opt,name=Items,proto3" json:"Items,omitempty"`
+	// Keys is the option for the key constraints of map fields.
+	Keys *ItemRules `protobuf:"bytes,30,opt,name=Keys,proto3" json:"Keys,omitempty"`
+	// Sensitive is the option for the field to be cleared in the responses.
+	Sensitive bool `protobuf:"varint,31,opt,name=Sensitive,proto3" json:"Sensitive,omitempty"`
+	// RevealRoles is the option for the caller roles, that are allowed to see
+	// the sensitive field.
+	RevealRoles []string `protobuf:"bytes,32,rep,name=RevealRoles,proto3" json:"RevealRoles,omitempty"`
+	// OutputOnly is populated from google.api.field_behavior OUTPUT_ONLY,
+	// the field is set by the server, and rejected in the requests.
+	OutputOnly bool `protobuf:"varint,33,opt,name=OutputOnly,proto3" json:"OutputOnly,omitempty"`
+	// InputOnly is populated from google.api.field_behavior INPUT_ONLY,
+	// the field is not included in the responses and the output.
+	InputOnly bool `protobuf:"varint,34,opt,name=InputOnly,proto3" json:"InputOnly,omitempty"`
+	// Immutable is populated from google.api.field_behavior IMMUTABLE,
+	// the field can be set only on creation.
+	Immutable     bool `protobuf:"varint,35,opt,name=Immutable,proto3" json:"Immutable,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *FieldMeta) Reset() {
 	*x = FieldMeta{}
-	mi := &file_annotations_proto_msgTypes[2]
+	mi := &file_annotations_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -295,7 +399,7 @@ func (x *FieldMeta) String() string {
 func (*FieldMeta) ProtoMessage() {}
 
 func (x *FieldMeta) ProtoReflect() protoreflect.Message {
-	mi := &file_annotations_proto_msgTypes[2]
+	mi := &file_annotations_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -308,7 +412,7 @@ func (x *FieldMeta) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FieldMeta.ProtoReflect.Descriptor instead.
 func (*FieldMeta) Descriptor() ([]byte, []int) {
-	return file_annotations_proto_rawDescGZIP(), []int{2}
+	return file_annotations_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *FieldMeta) GetName() string {
@@ -318,123 +422,613 @@ func (x *FieldMeta) GetName() string {
 	return ""
 }
 
-func (x *FieldMeta) GetFullName() string {
-	if x != nil {
-		return x.FullName
-	}
-	return ""
+func (x *FieldMeta) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *FieldMeta) GetDisplay() string {
+	if x != nil {
+		return x.Display
+	}
+	return ""
+}
+
+func (x *FieldMeta) GetDocumentation() string {
+	if x != nil {
+		return x.Documentation
+	}
+	return ""
+}
+
+func (x *FieldMeta) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *FieldMeta) GetSearchType() string {
+	if x != nil {
+		return x.SearchType
+	}
+	return ""
+}
+
+func (x *FieldMeta) GetSearchOptions() SearchOption_Enum {
+	if x != nil {
+		return x.SearchOptions
+	}
+	return SearchOption_None
+}
+
+func (x *FieldMeta) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *FieldMeta) GetRequiredOr() []string {
+	if x != nil {
+		return x.RequiredOr
+	}
+	return nil
+}
+
+func (x *FieldMeta) GetStructName() string {
+	if x != nil {
+		return x.StructName
+	}
+	return ""
+}
+
+func (x *FieldMeta) GetFields() []*FieldMeta {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *FieldMeta) GetEnumDescription() *EnumDescription {
+	if x != nil {
+		return x.EnumDescription
+	}
+	return nil
+}
+
+func (x *FieldMeta) GetMin() int32 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *FieldMeta) GetMax() int32 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *FieldMeta) GetMinCount() int32 {
+	if x != nil {
+		return x.MinCount
+	}
+	return 0
+}
+
+func (x *FieldMeta) GetMaxCount() int32 {
+	if x != nil {
+		return x.MaxCount
+	}
+	return 0
+}
+
+func (x *FieldMeta) GetDeprecated() bool {
+	if x != nil {
+		return x.Deprecated
+	}
+	return false
+}
+
+func (x *FieldMeta) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *FieldMeta) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *FieldMeta) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *FieldMeta) GetSuffix() string {
+	if x != nil {
+		return x.Suffix
+	}
+	return ""
+}
+
+func (x *FieldMeta) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *FieldMeta) GetIn() []string {
+	if x != nil {
+		return x.In
+	}
+	return nil
+}
+
+func (x *FieldMeta) GetNotIn() []string {
+	if x != nil {
+		return x.NotIn
+	}
+	return nil
+}
+
+func (x *FieldMeta) GetRange() *NumberRange {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+func (x *FieldMeta) GetTimeRange() *TimeRange {
+	if x != nil {
+		return x.TimeRange
+	}
+	return nil
+}
+
+func (x *FieldMeta) GetUnique() bool {
+	if x != nil {
+		return x.Unique
+	}
+	return false
+}
+
+func (x *FieldMeta) GetItems() *ItemRules {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *FieldMeta) GetKeys() *ItemRules {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *FieldMeta) GetSensitive() bool {
+	if x != nil {
+		return x.Sensitive
+	}
+	return false
+}
+
+func (x *FieldMeta) GetRevealRoles() []string {
+	if x != nil {
+		return x.RevealRoles
+	}
+	return nil
+}
+
+func (x *FieldMeta) GetOutputOnly() bool {
+	if x != nil {
+		return x.OutputOnly
+	}
+	return false
+}
+
+func (x *FieldMeta) GetInputOnly() bool {
+	if x != nil {
+		return x.InputOnly
+	}
+	return false
+}
+
+func (x *FieldMeta) GetImmutable() bool {
+	if x != nil {
+		return x.Immutable
+	}
+	return false
+}
+
+// ItemRules are the constraints of the repeated field items, or map keys.
+type ItemRules struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Min is the minimum length of strings.
+	Min int32 `protobuf:"varint,1,opt,name=Min,proto3" json:"Min,omitempty"`
+	// Max is the maximum length of strings.
+	Max int32 `protobuf:"varint,2,opt,name=Max,proto3" json:"Max,omitempty"`
+	// Pattern is the regular expression for strings to match.
+	Pattern string `protobuf:"bytes,3,opt,name=Pattern,proto3" json:"Pattern,omitempty"`
+	// Prefix is the value for strings to start with.
+	Prefix string `protobuf:"bytes,4,opt,name=Prefix,proto3" json:"Prefix,omitempty"`
+	// Suffix is the value for strings to end with.
+	Suffix string `protobuf:"bytes,5,opt,name=Suffix,proto3" json:"Suffix,omitempty"`
+	// Format is the well-known format of strings:
+	// email|uri|hostname|ip|ipv4|ipv6|uuid|ulid.
+	Format string `protobuf:"bytes,6,opt,name=Format,proto3" json:"Format,omitempty"`
+	// In is the list of allowed values, for strings, integers and enums.
+	In []string `protobuf:"bytes,7,rep,name=In,proto3" json:"In,omitempty"`
+	// NotIn is the list of denied values, for strings, integers and enums.
+	NotIn []string `protobuf:"bytes,8,rep,name=NotIn,proto3" json:"NotIn,omitempty"`
+	// Range is the range of numbers.
+	Range         *NumberRange `protobuf:"bytes,9,opt,name=Range,proto3" json:"Range,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ItemRules) Reset() {
+	*x = ItemRules{}
+	mi := &file_annotations_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItemRules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemRules) ProtoMessage() {}
+
+func (x *ItemRules) ProtoReflect() protoreflect.Message {
+	mi := &file_annotations_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItemRules.ProtoReflect.Descriptor instead.
+func (*ItemRules) Descriptor() ([]byte, []int) {
+	return file_annotations_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ItemRules) GetMin() int32 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *ItemRules) GetMax() int32 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *ItemRules) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *ItemRules) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ItemRules) GetSuffix() string {
+	if x != nil {
+		return x.Suffix
+	}
+	return ""
+}
+
+func (x *ItemRules) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ItemRules) GetIn() []string {
+	if x != nil {
+		return x.In
+	}
+	return nil
+}
+
+func (x *ItemRules) GetNotIn() []string {
+	if x != nil {
+		return x.NotIn
+	}
+	return nil
+}
+
+func (x *ItemRules) GetRange() *NumberRange {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+// NumberBound is the bound of NumberRange.
+type NumberBound struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Value is the bound value, the type does not need to match the field
+	// type, for example Float bound can be used for integer fields.
+	//
+	// Types that are valid to be assigned to Value:
+	//
+	//	*NumberBound_Int
+	//	*NumberBound_Uint
+	//	*NumberBound_Float
+	Value isNumberBound_Value `protobuf_oneof:"Value"`
+	// Exclusive specifies that the bound value itself is not allowed.
+	Exclusive     bool `protobuf:"varint,4,opt,name=Exclusive,proto3" json:"Exclusive,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NumberBound) Reset() {
+	*x = NumberBound{}
+	mi := &file_annotations_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NumberBound) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NumberBound) ProtoMessage() {}
+
+func (x *NumberBound) ProtoReflect() protoreflect.Message {
+	mi := &file_annotations_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NumberBound.ProtoReflect.Descriptor instead.
+func (*NumberBound) Descriptor() ([]byte, []int) {
+	return file_annotations_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *NumberBound) GetValue() isNumberBound_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *NumberBound) GetInt() int64 {
+	if x != nil {
+		if x, ok := x.Value.(*NumberBound_Int); ok {
+			return x.Int
+		}
+	}
+	return 0
+}
+
+func (x *NumberBound) GetUint() uint64 {
+	if x != nil {
+		if x, ok := x.Value.(*NumberBound_Uint); ok {
+			return x.Uint
+		}
+	}
+	return 0
+}
+
+func (x *NumberBound) GetFloat() float64 {
+	if x != nil {
+		if x, ok := x.Value.(*NumberBound_Float); ok {
+			return x.Float
+		}
+	}
+	return 0
+}
+
+func (x *NumberBound) GetExclusive() bool {
+	if x != nil {
+		return x.Exclusive
+	}
+	return false
+}
+
+type isNumberBound_Value interface {
+	isNumberBound_Value()
+}
+
+type NumberBound_Int struct {
+	Int int64 `protobuf:"varint,1,opt,name=Int,proto3,oneof"`
+}
+
+type NumberBound_Uint struct {
+	Uint uint64 `protobuf:"varint,2,opt,name=Uint,proto3,oneof"`
+}
+
+type NumberBound_Float struct {
+	Float float64 `protobuf:"fixed64,3,opt,name=Float,proto3,oneof"`
+}
+
+func (*NumberBound_Int) isNumberBound_Value() {}
+
+func (*NumberBound_Uint) isNumberBound_Value() {}
+
+func (*NumberBound_Float) isNumberBound_Value() {}
+
+// TimeRange is the range of google.protobuf.Timestamp and
+// google.protobuf.Duration values, the durations are in Go format, for
+// example "1h30m".
+type TimeRange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// LtNow specifies the Timestamp to be in the past.
+	LtNow bool `protobuf:"varint,1,opt,name=LtNow,proto3" json:"LtNow,omitempty"`
+	// GtNow specifies the Timestamp to be in the future.
+	GtNow bool `protobuf:"varint,2,opt,name=GtNow,proto3" json:"GtNow,omitempty"`
+	// NotBeforeNow specifies the Timestamp to be now or in the future.
+	NotBeforeNow bool `protobuf:"varint,3,opt,name=NotBeforeNow,proto3" json:"NotBeforeNow,omitempty"`
+	// Within specifies the Timestamp to be within the duration from now,
+	// in the past or in the future.
+	Within string `protobuf:"bytes,4,opt,name=Within,proto3" json:"Within,omitempty"`
+	// MinDuration is the minimum value of Duration.
+	MinDuration string `protobuf:"bytes,5,opt,name=MinDuration,proto3" json:"MinDuration,omitempty"`
+	// MaxDuration is the maximum value of Duration.
+	MaxDuration   string `protobuf:"bytes,6,opt,name=MaxDuration,proto3" json:"MaxDuration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimeRange) Reset() {
+	*x = TimeRange{}
+	mi := &file_annotations_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimeRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *FieldMeta) GetDisplay() string {
-	if x != nil {
-		return x.Display
-	}
-	return ""
-}
+func (*TimeRange) ProtoMessage() {}
 
-func (x *FieldMeta) GetDocumentation() string {
+func (x *TimeRange) ProtoReflect() protoreflect.Message {
+	mi := &file_annotations_proto_msgTypes[6]
 	if x != nil {
-		return x.Documentation
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *FieldMeta) GetType() string {
-	if x != nil {
-		return x.Type
-	}
-	return ""
+// Deprecated: Use TimeRange.ProtoReflect.Descriptor instead.
+func (*TimeRange) Descriptor() ([]byte, []int) {
+	return file_annotations_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *FieldMeta) GetSearchType() string {
+func (x *TimeRange) GetLtNow() bool {
 	if x != nil {
-		return x.SearchType
+		return x.LtNow
 	}
-	return ""
+	return false
 }
 
-func (x *FieldMeta) GetSearchOptions() SearchOption_Enum {
+func (x *TimeRange) GetGtNow() bool {
 	if x != nil {
-		return x.SearchOptions
+		return x.GtNow
 	}
-	return SearchOption_None
+	return false
 }
 
-func (x *FieldMeta) GetRequired() bool {
+func (x *TimeRange) GetNotBeforeNow() bool {
 	if x != nil {
-		return x.Required
+		return x.NotBeforeNow
 	}
 	return false
 }
 
-func (x *FieldMeta) GetRequiredOr() []string {
+func (x *TimeRange) GetWithin() string {
 	if x != nil {
-		return x.RequiredOr
+		return x.Within
 	}
-	return nil
+	return ""
 }
 
-func (x *FieldMeta) GetStructName() string {
+func (x *TimeRange) GetMinDuration() string {
 	if x != nil {
-		return x.StructName
+		return x.MinDuration
 	}
 	return ""
 }
 
-func (x *FieldMeta) GetFields() []*FieldMeta {
+func (x *TimeRange) GetMaxDuration() string {
 	if x != nil {
-		return x.Fields
+		return x.MaxDuration
 	}
-	return nil
+	return ""
 }
 
-func (x *FieldMeta) GetEnumDescription() *EnumDescription {
-	if x != nil {
-		return x.EnumDescription
-	}
-	return nil
+// NumberRange is the range of number values, the bounds are inclusive,
+// unless Exclusive is set, and are checked only if present.
+type NumberRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Min           *NumberBound           `protobuf:"bytes,1,opt,name=Min,proto3" json:"Min,omitempty"`
+	Max           *NumberBound           `protobuf:"bytes,2,opt,name=Max,proto3" json:"Max,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FieldMeta) GetMin() int32 {
-	if x != nil {
-		return x.Min
-	}
-	return 0
+func (x *NumberRange) Reset() {
+	*x = NumberRange{}
+	mi := &file_annotations_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *FieldMeta) GetMax() int32 {
-	if x != nil {
-		return x.Max
-	}
-	return 0
+func (x *NumberRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *FieldMeta) GetMinCount() int32 {
+func (*NumberRange) ProtoMessage() {}
+
+func (x *NumberRange) ProtoReflect() protoreflect.Message {
+	mi := &file_annotations_proto_msgTypes[7]
 	if x != nil {
-		return x.MinCount
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *FieldMeta) GetMaxCount() int32 {
-	if x != nil {
-		return x.MaxCount
-	}
-	return 0
+// Deprecated: Use NumberRange.ProtoReflect.Descriptor instead.
+func (*NumberRange) Descriptor() ([]byte, []int) {
+	return file_annotations_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *FieldMeta) GetDeprecated() bool {
+func (x *NumberRange) GetMin() *NumberBound {
 	if x != nil {
-		return x.Deprecated
+		return x.Min
 	}
-	return false
+	return nil
 }
 
-func (x *FieldMeta) GetAlias() string {
+func (x *NumberRange) GetMax() *NumberBound {
 	if x != nil {
-		return x.Alias
+		return x.Max
 	}
-	return ""
+	return nil
 }
 
 type EnumDescription struct {
@@ -451,7 +1045,7 @@ type EnumDescription struct {
 
 func (x *EnumDescription) Reset() {
 	*x = EnumDescription{}
-	mi := &file_annotations_proto_msgTypes[3]
+	mi := &file_annotations_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -463,7 +1057,7 @@ func (x *EnumDescription) String() string {
 func (*EnumDescription) ProtoMessage() {}
 
 func (x *EnumDescription) ProtoReflect() protoreflect.Message {
-	mi := &file_annotations_proto_msgTypes[3]
+	mi := &file_annotations_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -476,7 +1070,7 @@ func (x *EnumDescription) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnumDescription.ProtoReflect.Descriptor instead.
 func (*EnumDescription) Descriptor() ([]byte, []int) {
-	return file_annotations_proto_rawDescGZIP(), []int{3}
+	return file_annotations_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *EnumDescription) GetName() string {
@@ -522,14 +1116,18 @@ type MessageDescription struct {
 	Documentation string                 `protobuf:"bytes,4,opt,name=Documentation,proto3" json:"Documentation,omitempty"`
 	FullName      string                 `protobuf:"bytes,5,opt,name=FullName,proto3" json:"FullName,omitempty"`
 	// Deprecated is the option for the message to be deprecated.
-	Deprecated    bool `protobuf:"varint,8,opt,name=Deprecated,proto3" json:"Deprecated,omitempty"`
+	Deprecated bool `protobuf:"varint,8,opt,name=Deprecated,proto3" json:"Deprecated,omitempty"`
+	// Rules is the list of the message-level validation rules.
+	Rules []*MessageRule `protobuf:"bytes,9,rep,name=Rules,proto3" json:"Rules,omitempty"`
+	// Oneofs is the list of the message oneof groups.
+	Oneofs        []*OneofMeta `protobuf:"bytes,10,rep,name=Oneofs,proto3" json:"Oneofs,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *MessageDescription) Reset() {
 	*x = MessageDescription{}
-	mi := &file_annotations_proto_msgTypes[4]
+	mi := &file_annotations_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -541,7 +1139,7 @@ func (x *MessageDescription) String() string {
 func (*MessageDescription) ProtoMessage() {}
 
 func (x *MessageDescription) ProtoReflect() protoreflect.Message {
-	mi := &file_annotations_proto_msgTypes[4]
+	mi := &file_annotations_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -554,7 +1152,7 @@ func (x *MessageDescription) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MessageDescription.ProtoReflect.Descriptor instead.
 func (*MessageDescription) Descriptor() ([]byte, []int) {
-	return file_annotations_proto_rawDescGZIP(), []int{4}
+	return file_annotations_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *MessageDescription) GetName() string {
@@ -599,6 +1197,83 @@ func (x *MessageDescription) GetDeprecated() bool {
 	return false
 }
 
+func (x *MessageDescription) GetRules() []*MessageRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *MessageDescription) GetOneofs() []*OneofMeta {
+	if x != nil {
+		return x.Oneofs
+	}
+	return nil
+}
+
+// OneofMeta describes the oneof group of the message.
+type OneofMeta struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	// Fields is the list of the member field names.
+	Fields []string `protobuf:"bytes,2,rep,name=Fields,proto3" json:"Fields,omitempty"`
+	// Required is the option for exactly one of the fields to be set.
+	Required      bool `protobuf:"varint,3,opt,name=Required,proto3" json:"Required,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OneofMeta) Reset() {
+	*x = OneofMeta{}
+	mi := &file_annotations_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OneofMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OneofMeta) ProtoMessage() {}
+
+func (x *OneofMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_annotations_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OneofMeta.ProtoReflect.Descriptor instead.
+func (*OneofMeta) Descriptor() ([]byte, []int) {
+	return file_annotations_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *OneofMeta) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *OneofMeta) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *OneofMeta) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
 var file_annotations_proto_extTypes = []protoimpl.ExtensionInfo{
 	{
 		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
@@ -632,6 +1307,14 @@ var file_annotations_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "bytes,1074,opt,name=scopes",
 		Filename:      "annotations.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         55001,
+		Name:          "es.api.validate_requests",
+		Tag:           "varint,55001,opt,name=validate_requests",
+		Filename:      "annotations.proto",
+	},
 	{
 		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
 		ExtensionType: (*string)(nil),
@@ -712,6 +1395,126 @@ var file_annotations_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "bytes,51011,opt,name=alias",
 		Filename:      "annotations.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         51012,
+		Name:          "es.api.pattern",
+		Tag:           "bytes,51012,opt,name=pattern",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         51013,
+		Name:          "es.api.prefix",
+		Tag:           "bytes,51013,opt,name=prefix",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         51014,
+		Name:          "es.api.suffix",
+		Tag:           "bytes,51014,opt,name=suffix",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         51015,
+		Name:          "es.api.format",
+		Tag:           "bytes,51015,opt,name=format",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         51016,
+		Name:          "es.api.in",
+		Tag:           "bytes,51016,opt,name=in",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         51017,
+		Name:          "es.api.not_in",
+		Tag:           "bytes,51017,opt,name=not_in",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*NumberRange)(nil),
+		Field:         51018,
+		Name:          "es.api.range",
+		Tag:           "bytes,51018,opt,name=range",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*TimeRange)(nil),
+		Field:         51019,
+		Name:          "es.api.time_range",
+		Tag:           "bytes,51019,opt,name=time_range",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         51020,
+		Name:          "es.api.unique",
+		Tag:           "varint,51020,opt,name=unique",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*ItemRules)(nil),
+		Field:         51021,
+		Name:          "es.api.items",
+		Tag:           "bytes,51021,opt,name=items",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*ItemRules)(nil),
+		Field:         51022,
+		Name:          "es.api.keys",
+		Tag:           "bytes,51022,opt,name=keys",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         51023,
+		Name:          "es.api.sensitive",
+		Tag:           "varint,51023,opt,name=sensitive",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         51024,
+		Name:          "es.api.reveal_roles",
+		Tag:           "bytes,51024,opt,name=reveal_roles",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         51025,
+		Name:          "es.api.model_tags",
+		Tag:           "bytes,51025,opt,name=model_tags",
+		Filename:      "annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.OneofOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         56001,
+		Name:          "es.api.oneof_required",
+		Tag:           "varint,56001,opt,name=oneof_required",
+		Filename:      "annotations.proto",
+	},
 	{
 		ExtendedType:  (*descriptorpb.EnumOptions)(nil),
 		ExtensionType: (*bool)(nil),
@@ -792,6 +1595,14 @@ var file_annotations_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "varint,53004,opt,name=generate_model",
 		Filename:      "annotations.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: ([]*MessageRule)(nil),
+		Field:         53005,
+		Name:          "es.api.rules",
+		Tag:           "bytes,53005,rep,name=rules",
+		Filename:      "annotations.proto",
+	},
 }
 
 // Extension fields to descriptorpb.MethodOptions.
@@ -820,6 +1631,16 @@ var (
 	E_Scopes = &file_annotations_proto_extTypes[3]
 )
 
+// Extension fields to descriptorpb.ServiceOptions.
+var (
+	// validate_requests is the option for the generated HTTP handlers to
+	// validate requests before calling the service. If set, it overrides the
+	// `validate` flag of protoc-gen-go-http.
+	//
+	// optional bool validate_requests = 55001;
+	E_ValidateRequests = &file_annotations_proto_extTypes[4]
+)
+
 // Extension fields to descriptorpb.FieldOptions.
 var (
 	// search is the option for OpenSearch Index.
@@ -839,48 +1660,138 @@ var (
 	// keyword|text|integer|float|double|boolean|date|geo_point|ip.
 	//
 	// optional string search = 51001;
-	E_Search = &file_annotations_proto_extTypes[4]
+	E_Search = &file_annotations_proto_extTypes[5]
 	// display is the option for the field's Display Name in the UI.
 	//
 	// optional string display = 51002;
-	E_Display = &file_annotations_proto_extTypes[5]
+	E_Display = &file_annotations_proto_extTypes[6]
 	// description is the option for the field's description.
 	//
 	// optional string description = 51003;
-	E_Description = &file_annotations_proto_extTypes[6]
+	E_Description = &file_annotations_proto_extTypes[7]
 	// required is the option for the field to be required.
 	//
 	// optional bool required = 51005;
-	E_Required = &file_annotations_proto_extTypes[7]
+	E_Required = &file_annotations_proto_extTypes[8]
 	// required_or is the option for the field to be required, if one of the
 	// other values is provided.
 	// Comma-separated list of field names that are required if this field is
 	// not provided. For example, "field1,field2" will be parsed as a list.
 	//
 	// optional string required_or = 51006;
-	E_RequiredOr = &file_annotations_proto_extTypes[8]
+	E_RequiredOr = &file_annotations_proto_extTypes[9]
 	// min is the option for the field minimum length for strings, and minimum
 	// value for numbers.
 	//
 	// optional int32 min = 51007;
-	E_Min = &file_annotations_proto_extTypes[9]
+	E_Min = &file_annotations_proto_extTypes[10]
 	// max is the option for the field maximum length for strings, and maximum
 	// value for numbers.
 	//
 	// optional int32 max = 51008;
-	E_Max = &file_annotations_proto_extTypes[10]
+	E_Max = &file_annotations_proto_extTypes[11]
 	// min_count is the option for the field minimum count for lists.
 	//
 	// optional int32 min_count = 51009;
-	E_MinCount = &file_annotations_proto_extTypes[11]
+	E_MinCount = &file_annotations_proto_extTypes[12]
 	// max_count is the option for the field maximum count for lists.
 	//
 	// optional int32 max_count = 51010;
-	E_MaxCount = &file_annotations_proto_extTypes[12]
+	E_MaxCount = &file_annotations_proto_extTypes[13]
 	// alias is the option for the field alias name for the search query.
 	//
 	// optional string alias = 51011;
-	E_Alias = &file_annotations_proto_extTypes[13]
+	E_Alias = &file_annotations_proto_extTypes[14]
+	// pattern is the option for the field to match the regular expression,
+	// in RE2 syntax, for strings.
+	//
+	// optional string pattern = 51012;
+	E_Pattern = &file_annotations_proto_extTypes[15]
+	// prefix is the option for the field to start with the value, for strings.
+	//
+	// optional string prefix = 51013;
+	E_Prefix = &file_annotations_proto_extTypes[16]
+	// suffix is the option for the field to end with the value, for strings.
+	//
+	// optional string suffix = 51014;
+	E_Suffix = &file_annotations_proto_extTypes[17]
+	// format is the option for the field to be in a well-known format, for
+	// strings. It can be one of the following:
+	// email|uri|hostname|ip|ipv4|ipv6|uuid|ulid.
+	//
+	// optional string format = 51015;
+	E_Format = &file_annotations_proto_extTypes[18]
+	// in is the option for the field value to be one of the listed values,
+	// for enums, strings and integers.
+	// Comma-separated list of values, enum values can be specified by name or
+	// number. For example, "Foo,Bar" will be parsed as a list.
+	//
+	// optional string in = 51016;
+	E_In = &file_annotations_proto_extTypes[19]
+	// not_in is the option for the field value to not be one of the listed
+	// values, for enums, strings and integers.
+	// Comma-separated list of values, enum values can be specified by name or
+	// number.
+	//
+	// optional string not_in = 51017;
+	E_NotIn = &file_annotations_proto_extTypes[20]
+	// range is the option for the field value range of numbers, with 64-bit
+	// and fractional bounds, for example:
+	// (es.api.range) = { Min: { Int: 0 }, Max: { Float: 1.5, Exclusive: true } }
+	// Unlike min and max, a bound of 0 is checked, if it is set.
+	//
+	// optional es.api.NumberRange range = 51018;
+	E_Range = &file_annotations_proto_extTypes[21]
+	// time_range is the option for the google.protobuf.Timestamp and
+	// google.protobuf.Duration field bounds, for example:
+	// (es.api.time_range) = { LtNow: true, Within: "720h" }
+	//
+	// optional es.api.TimeRange time_range = 51019;
+	E_TimeRange = &file_annotations_proto_extTypes[22]
+	// unique is the option for the items of repeated scalar and enum fields
+	// to be unique.
+	//
+	// optional bool unique = 51020;
+	E_Unique = &file_annotations_proto_extTypes[23]
+	// items is the option for the item constraints of repeated fields,
+	// min, max, pattern and other value options can not be used with
+	// repeated fields, for example:
+	// (es.api.items) = { Min: 3, Pattern: "^[a-z]+$" }
+	//
+	// optional es.api.ItemRules items = 51021;
+	E_Items = &file_annotations_proto_extTypes[24]
+	// keys is the option for the key constraints of map fields, for example:
+	// (es.api.keys) = { Max: 64, In: ["foo", "bar"] }
+	//
+	// optional es.api.ItemRules keys = 51022;
+	E_Keys = &file_annotations_proto_extTypes[25]
+	// sensitive is the option for the field to be cleared in the responses,
+	// unless the caller has one of reveal_roles, see api.RedactResponse.
+	//
+	// optional bool sensitive = 51023;
+	E_Sensitive = &file_annotations_proto_extTypes[26]
+	// reveal_roles is the option for the comma separated list of the caller
+	// roles, that are allowed to see the sensitive field, for example:
+	// (es.api.reveal_roles) = "Admin,Auditor"
+	//
+	// optional string reveal_roles = 51024;
+	E_RevealRoles = &file_annotations_proto_extTypes[27]
+	// model_tags is the option for the additional struct tags of the field
+	// in the generated models, in the struct tag format, for example:
+	// (es.api.model_tags) = "db:\"created_at\" bson:\"-\""
+	// The tags override the tags generated with model-tags plugin option.
+	//
+	// optional string model_tags = 51025;
+	E_ModelTags = &file_annotations_proto_extTypes[28]
+)
+
+// Extension fields to descriptorpb.OneofOptions.
+var (
+	// oneof_required is the option for exactly one of the oneof fields to be
+	// set.
+	//
+	// optional bool oneof_required = 56001;
+	E_OneofRequired = &file_annotations_proto_extTypes[29]
 )
 
 // Extension fields to descriptorpb.EnumOptions.
@@ -888,7 +1799,7 @@ var (
 	// is_bitmask marks the enum as a bitmask enum.
 	//
 	// optional bool is_bitmask = 54001;
-	E_IsBitmask = &file_annotations_proto_extTypes[14]
+	E_IsBitmask = &file_annotations_proto_extTypes[30]
 )
 
 // Extension fields to descriptorpb.EnumValueOptions.
@@ -899,24 +1810,24 @@ var (
 	// For example, "arg1,arg2,arg3" will be parsed as a list of strings
 	//
 	// optional string enum_args = 52001;
-	E_EnumArgs = &file_annotations_proto_extTypes[15]
+	E_EnumArgs = &file_annotations_proto_extTypes[31]
 	// enum_display is the option for the field's Display Name in the UI.
 	//
 	// optional string enum_display = 52002;
-	E_EnumDisplay = &file_annotations_proto_extTypes[16]
+	E_EnumDisplay = &file_annotations_proto_extTypes[32]
 	// enum_description is the option for the field's description.
 	//
 	// optional string enum_description = 52003;
-	E_EnumDescription = &file_annotations_proto_extTypes[17]
+	E_EnumDescription = &file_annotations_proto_extTypes[33]
 	// enum_group is the option for the field's group name.
 	//
 	// optional string enum_group = 52004;
-	E_EnumGroup = &file_annotations_proto_extTypes[18]
+	E_EnumGroup = &file_annotations_proto_extTypes[34]
 	// opts is the miscellaneous options for the enum,
 	// For example, "arg1,arg2,arg3" will be parsed as a list of strings
 	//
 	// optional string enum_opts = 52005;
-	E_EnumOpts = &file_annotations_proto_extTypes[19]
+	E_EnumOpts = &file_annotations_proto_extTypes[35]
 )
 
 // Extension fields to descriptorpb.MessageOptions.
@@ -925,27 +1836,40 @@ var (
 	// information. By default, only for Request and Response messages.
 	//
 	// optional bool generate_meta = 53001;
-	E_GenerateMeta = &file_annotations_proto_extTypes[20]
+	E_GenerateMeta = &file_annotations_proto_extTypes[36]
 	// message_display is the option for the message's Display Name in the UI.
 	//
 	// optional string message_display = 53002;
-	E_MessageDisplay = &file_annotations_proto_extTypes[21]
+	E_MessageDisplay = &file_annotations_proto_extTypes[37]
 	// message_description is the option for the message's description.
 	//
 	// optional string message_description = 53003;
-	E_MessageDescription = &file_annotations_proto_extTypes[22]
+	E_MessageDescription = &file_annotations_proto_extTypes[38]
 	// generate_model is the option for generating the message's model
 	// for search index.
 	//
 	// optional bool generate_model = 53004;
-	E_GenerateModel = &file_annotations_proto_extTypes[23]
+	E_GenerateModel = &file_annotations_proto_extTypes[39]
+	// rules is the option for the message-level validation rules, with CEL
+	// expressions evaluated after the field constraints, for example:
+	// option (es.api.rules) = {
+	//     Expr: "this.EndTime > this.StartTime"
+	//     Message: "EndTime must be after StartTime"
+	// };
+	//
+	// repeated es.api.MessageRule rules = 53005;
+	E_Rules = &file_annotations_proto_extTypes[40]
 )
 
 var File_annotations_proto protoreflect.FileDescriptor
 
 const file_annotations_proto_rawDesc = "" +
 	"\n" +
-	"\x11annotations.proto\x12\x06es.api\x1a google/protobuf/descriptor.proto\"\xd4\x01\n" +
+	"\x11annotations.proto\x12\x06es.api\x1a google/protobuf/descriptor.proto\"K\n" +
+	"\vMessageRule\x12\x0e\n" +
+	"\x02ID\x18\x01 \x01(\tR\x02ID\x12\x12\n" +
+	"\x04Expr\x18\x02 \x01(\tR\x04Expr\x12\x18\n" +
+	"\aMessage\x18\x03 \x01(\tR\aMessage\"\xd4\x01\n" +
 	"\bEnumMeta\x12\x14\n" +
 	"\x05Value\x18\x01 \x01(\x05R\x05Value\x12\x12\n" +
 	"\x04Name\x18\x02 \x01(\tR\x04Name\x12\x1a\n" +
@@ -966,7 +1890,7 @@ const file_annotations_proto_rawDesc = "" +
 	"\n" +
 	"\x06Hidden\x10 \x12\x0f\n" +
 	"\vWithKeyword\x10@\x12\r\n" +
-	"\bWithText\x10\x80\x01\"\xcc\x04\n" +
+	"\bWithText\x10\x80\x01\"\xb4\b\n" +
 	"\tFieldMeta\x12\x12\n" +
 	"\x04Name\x18\x01 \x01(\tR\x04Name\x12\x1a\n" +
 	"\bFullName\x18\x02 \x01(\tR\bFullName\x12\x18\n" +
@@ -994,13 +1918,57 @@ const file_annotations_proto_rawDesc = "" +
 	"\n" +
 	"Deprecated\x18\x12 \x01(\bR\n" +
 	"Deprecated\x12\x14\n" +
-	"\x05Alias\x18\x13 \x01(\tR\x05Alias\"\xad\x01\n" +
+	"\x05Alias\x18\x13 \x01(\tR\x05Alias\x12\x18\n" +
+	"\aPattern\x18\x14 \x01(\tR\aPattern\x12\x16\n" +
+	"\x06Prefix\x18\x15 \x01(\tR\x06Prefix\x12\x16\n" +
+	"\x06Suffix\x18\x16 \x01(\tR\x06Suffix\x12\x16\n" +
+	"\x06Format\x18\x17 \x01(\tR\x06Format\x12\x0e\n" +
+	"\x02In\x18\x18 \x03(\tR\x02In\x12\x14\n" +
+	"\x05NotIn\x18\x19 \x03(\tR\x05NotIn\x12)\n" +
+	"\x05Range\x18\x1a \x01(\v2\x13.es.api.NumberRangeR\x05Range\x12/\n" +
+	"\tTimeRange\x18\x1b \x01(\v2\x11.es.api.TimeRangeR\tTimeRange\x12\x16\n" +
+	"\x06Unique\x18\x1c \x01(\bR\x06Unique\x12'\n" +
+	"\x05Items\x18\x1d \x01(\v2\x11.es.api.ItemRulesR\x05Items\x12%\n" +
+	"\x04Keys\x18\x1e \x01(\v2\x11.es.api.ItemRulesR\x04Keys\x12\x1c\n" +
+	"\tSensitive\x18\x1f \x01(\bR\tSensitive\x12 \n" +
+	"\vRevealRoles\x18  \x03(\tR\vRevealRoles\x12\x1e\n" +
+	"\n" +
+	"OutputOnly\x18! \x01(\bR\n" +
+	"OutputOnly\x12\x1c\n" +
+	"\tInputOnly\x18\" \x01(\bR\tInputOnly\x12\x1c\n" +
+	"\tImmutable\x18# \x01(\bR\tImmutable\"\xe2\x01\n" +
+	"\tItemRules\x12\x10\n" +
+	"\x03Min\x18\x01 \x01(\x05R\x03Min\x12\x10\n" +
+	"\x03Max\x18\x02 \x01(\x05R\x03Max\x12\x18\n" +
+	"\aPattern\x18\x03 \x01(\tR\aPattern\x12\x16\n" +
+	"\x06Prefix\x18\x04 \x01(\tR\x06Prefix\x12\x16\n" +
+	"\x06Suffix\x18\x05 \x01(\tR\x06Suffix\x12\x16\n" +
+	"\x06Format\x18\x06 \x01(\tR\x06Format\x12\x0e\n" +
+	"\x02In\x18\a \x03(\tR\x02In\x12\x14\n" +
+	"\x05NotIn\x18\b \x03(\tR\x05NotIn\x12)\n" +
+	"\x05Range\x18\t \x01(\v2\x13.es.api.NumberRangeR\x05Range\"v\n" +
+	"\vNumberBound\x12\x12\n" +
+	"\x03Int\x18\x01 \x01(\x03H\x00R\x03Int\x12\x14\n" +
+	"\x04Uint\x18\x02 \x01(\x04H\x00R\x04Uint\x12\x16\n" +
+	"\x05Float\x18\x03 \x01(\x01H\x00R\x05Float\x12\x1c\n" +
+	"\tExclusive\x18\x04 \x01(\bR\tExclusiveB\a\n" +
+	"\x05Value\"\xb7\x01\n" +
+	"\tTimeRange\x12\x14\n" +
+	"\x05LtNow\x18\x01 \x01(\bR\x05LtNow\x12\x14\n" +
+	"\x05GtNow\x18\x02 \x01(\bR\x05GtNow\x12\"\n" +
+	"\fNotBeforeNow\x18\x03 \x01(\bR\fNotBeforeNow\x12\x16\n" +
+	"\x06Within\x18\x04 \x01(\tR\x06Within\x12 \n" +
+	"\vMinDuration\x18\x05 \x01(\tR\vMinDuration\x12 \n" +
+	"\vMaxDuration\x18\x06 \x01(\tR\vMaxDuration\"[\n" +
+	"\vNumberRange\x12%\n" +
+	"\x03Min\x18\x01 \x01(\v2\x13.es.api.NumberBoundR\x03Min\x12%\n" +
+	"\x03Max\x18\x02 \x01(\v2\x13.es.api.NumberBoundR\x03Max\"\xad\x01\n" +
 	"\x0fEnumDescription\x12\x12\n" +
 	"\x04Name\x18\x01 \x01(\tR\x04Name\x12&\n" +
 	"\x05Enums\x18\x02 \x03(\v2\x10.es.api.EnumMetaR\x05Enums\x12$\n" +
 	"\rDocumentation\x18\x03 \x01(\tR\rDocumentation\x12\x1c\n" +
 	"\tIsBitmask\x18\x04 \x01(\bR\tIsBitmask\x12\x1a\n" +
-	"\bFullName\x18\x05 \x01(\tR\bFullName\"\xcf\x01\n" +
+	"\bFullName\x18\x05 \x01(\tR\bFullName\"\xa5\x02\n" +
 	"\x12MessageDescription\x12\x12\n" +
 	"\x04Name\x18\x01 \x01(\tR\x04Name\x12\x18\n" +
 	"\aDisplay\x18\x02 \x01(\tR\aDisplay\x12)\n" +
@@ -1009,11 +1977,19 @@ const file_annotations_proto_rawDesc = "" +
 	"\bFullName\x18\x05 \x01(\tR\bFullName\x12\x1e\n" +
 	"\n" +
 	"Deprecated\x18\b \x01(\bR\n" +
-	"Deprecated:D\n" +
+	"Deprecated\x12)\n" +
+	"\x05Rules\x18\t \x03(\v2\x13.es.api.MessageRuleR\x05Rules\x12)\n" +
+	"\x06Oneofs\x18\n" +
+	" \x03(\v2\x11.es.api.OneofMetaR\x06Oneofs\"S\n" +
+	"\tOneofMeta\x12\x12\n" +
+	"\x04Name\x18\x01 \x01(\tR\x04Name\x12\x16\n" +
+	"\x06Fields\x18\x02 \x03(\tR\x06Fields\x12\x1a\n" +
+	"\bRequired\x18\x03 \x01(\bR\bRequired:D\n" +
 	"\rallowed_roles\x12\x1e.google.protobuf.MethodOptions\x18\xaf\b \x01(\tR\fallowedRoles:8\n" +
 	"\acli_cmd\x12\x1e.google.protobuf.MethodOptions\x18\xb0\b \x01(\tR\x06cliCmd:J\n" +
 	"\x10refresh_interval\x12\x1e.google.protobuf.MethodOptions\x18\xb1\b \x01(\x05R\x0frefreshInterval:7\n" +
-	"\x06scopes\x12\x1e.google.protobuf.MethodOptions\x18\xb2\b \x01(\tR\x06scopes:7\n" +
+	"\x06scopes\x12\x1e.google.protobuf.MethodOptions\x18\xb2\b \x01(\tR\x06scopes:N\n" +
+	"\x11validate_requests\x12\x1f.google.protobuf.ServiceOptions\x18٭\x03 \x01(\bR\x10validateRequests:7\n" +
 	"\x06search\x12\x1d.google.protobuf.FieldOptions\x18\xb9\x8e\x03 \x01(\tR\x06search:9\n" +
 	"\adisplay\x12\x1d.google.protobuf.FieldOptions\x18\xba\x8e\x03 \x01(\tR\adisplay:A\n" +
 	"\vdescription\x12\x1d.google.protobuf.FieldOptions\x18\xbb\x8e\x03 \x01(\tR\vdescription:;\n" +
@@ -1024,7 +2000,24 @@ const file_annotations_proto_rawDesc = "" +
 	"\x03max\x12\x1d.google.protobuf.FieldOptions\x18\xc0\x8e\x03 \x01(\x05R\x03max:<\n" +
 	"\tmin_count\x12\x1d.google.protobuf.FieldOptions\x18\xc1\x8e\x03 \x01(\x05R\bminCount:<\n" +
 	"\tmax_count\x12\x1d.google.protobuf.FieldOptions\x18\u008e\x03 \x01(\x05R\bmaxCount:5\n" +
-	"\x05alias\x12\x1d.google.protobuf.FieldOptions\x18Î\x03 \x01(\tR\x05alias:=\n" +
+	"\x05alias\x12\x1d.google.protobuf.FieldOptions\x18Î\x03 \x01(\tR\x05alias:9\n" +
+	"\apattern\x12\x1d.google.protobuf.FieldOptions\x18Ď\x03 \x01(\tR\apattern:7\n" +
+	"\x06prefix\x12\x1d.google.protobuf.FieldOptions\x18Ŏ\x03 \x01(\tR\x06prefix:7\n" +
+	"\x06suffix\x12\x1d.google.protobuf.FieldOptions\x18Ǝ\x03 \x01(\tR\x06suffix:7\n" +
+	"\x06format\x12\x1d.google.protobuf.FieldOptions\x18ǎ\x03 \x01(\tR\x06format:/\n" +
+	"\x02in\x12\x1d.google.protobuf.FieldOptions\x18Ȏ\x03 \x01(\tR\x02in:6\n" +
+	"\x06not_in\x12\x1d.google.protobuf.FieldOptions\x18Ɏ\x03 \x01(\tR\x05notIn:J\n" +
+	"\x05range\x12\x1d.google.protobuf.FieldOptions\x18ʎ\x03 \x01(\v2\x13.es.api.NumberRangeR\x05range:Q\n" +
+	"\n" +
+	"time_range\x12\x1d.google.protobuf.FieldOptions\x18ˎ\x03 \x01(\v2\x11.es.api.TimeRangeR\ttimeRange:7\n" +
+	"\x06unique\x12\x1d.google.protobuf.FieldOptions\x18̎\x03 \x01(\bR\x06unique:H\n" +
+	"\x05items\x12\x1d.google.protobuf.FieldOptions\x18͎\x03 \x01(\v2\x11.es.api.ItemRulesR\x05items:F\n" +
+	"\x04keys\x12\x1d.google.protobuf.FieldOptions\x18Ύ\x03 \x01(\v2\x11.es.api.ItemRulesR\x04keys:=\n" +
+	"\tsensitive\x12\x1d.google.protobuf.FieldOptions\x18ώ\x03 \x01(\bR\tsensitive:B\n" +
+	"\freveal_roles\x12\x1d.google.protobuf.FieldOptions\x18Ў\x03 \x01(\tR\vrevealRoles:>\n" +
+	"\n" +
+	"model_tags\x12\x1d.google.protobuf.FieldOptions\x18ю\x03 \x01(\tR\tmodelTags:F\n" +
+	"\x0eoneof_required\x12\x1d.google.protobuf.OneofOptions\x18\xc1\xb5\x03 \x01(\bR\roneofRequired:=\n" +
 	"\n" +
 	"is_bitmask\x12\x1c.google.protobuf.EnumOptions\x18\xf1\xa5\x03 \x01(\bR\tisBitmask:@\n" +
 	"\tenum_args\x12!.google.protobuf.EnumValueOptions\x18\xa1\x96\x03 \x01(\tR\benumArgs:F\n" +
@@ -1036,7 +2029,8 @@ const file_annotations_proto_rawDesc = "" +
 	"\rgenerate_meta\x12\x1f.google.protobuf.MessageOptions\x18\x89\x9e\x03 \x01(\bR\fgenerateMeta:J\n" +
 	"\x0fmessage_display\x12\x1f.google.protobuf.MessageOptions\x18\x8a\x9e\x03 \x01(\tR\x0emessageDisplay:R\n" +
 	"\x13message_description\x12\x1f.google.protobuf.MessageOptions\x18\x8b\x9e\x03 \x01(\tR\x12messageDescription:H\n" +
-	"\x0egenerate_model\x12\x1f.google.protobuf.MessageOptions\x18\x8c\x9e\x03 \x01(\bR\rgenerateModelB1Z/github.com/effective-security/protoc-gen-go/apib\x06proto3"
+	"\x0egenerate_model\x12\x1f.google.protobuf.MessageOptions\x18\x8c\x9e\x03 \x01(\bR\rgenerateModel:L\n" +
+	"\x05rules\x12\x1f.google.protobuf.MessageOptions\x18\x8d\x9e\x03 \x03(\v2\x13.es.api.MessageRuleR\x05rulesB1Z/github.com/effective-security/protoc-gen-go/apib\x06proto3"
 
 var (
 	file_annotations_proto_rawDescOnce sync.Once
@@ -1051,55 +2045,94 @@ func file_annotations_proto_rawDescGZIP() []byte {
 }
 
 var file_annotations_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_annotations_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_annotations_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_annotations_proto_goTypes = []any{
 	(SearchOption_Enum)(0),                // 0: es.api.SearchOption.Enum
-	(*EnumMeta)(nil),                      // 1: es.api.EnumMeta
-	(*SearchOption)(nil),                  // 2: es.api.SearchOption
-	(*FieldMeta)(nil),                     // 3: es.api.FieldMeta
-	(*EnumDescription)(nil),               // 4: es.api.EnumDescription
-	(*MessageDescription)(nil),            // 5: es.api.MessageDescription
-	(*descriptorpb.MethodOptions)(nil),    // 6: google.protobuf.MethodOptions
-	(*descriptorpb.FieldOptions)(nil),     // 7: google.protobuf.FieldOptions
-	(*descriptorpb.EnumOptions)(nil),      // 8: google.protobuf.EnumOptions
-	(*descriptorpb.EnumValueOptions)(nil), // 9: google.protobuf.EnumValueOptions
-	(*descriptorpb.MessageOptions)(nil),   // 10: google.protobuf.MessageOptions
+	(*MessageRule)(nil),                   // 1: es.api.MessageRule
+	(*EnumMeta)(nil),                      // 2: es.api.EnumMeta
+	(*SearchOption)(nil),                  // 3: es.api.SearchOption
+	(*FieldMeta)(nil),                     // 4: es.api.FieldMeta
+	(*ItemRules)(nil),                     // 5: es.api.ItemRules
+	(*NumberBound)(nil),                   // 6: es.api.NumberBound
+	(*TimeRange)(nil),                     // 7: es.api.TimeRange
+	(*NumberRange)(nil),                   // 8: es.api.NumberRange
+	(*EnumDescription)(nil),               // 9: es.api.EnumDescription
+	(*MessageDescription)(nil),            // 10: es.api.MessageDescription
+	(*OneofMeta)(nil),                     // 11: es.api.OneofMeta
+	(*descriptorpb.MethodOptions)(nil),    // 12: google.protobuf.MethodOptions
+	(*descriptorpb.ServiceOptions)(nil),   // 13: google.protobuf.ServiceOptions
+	(*descriptorpb.FieldOptions)(nil),     // 14: google.protobuf.FieldOptions
+	(*descriptorpb.OneofOptions)(nil),     // 15: google.protobuf.OneofOptions
+	(*descriptorpb.EnumOptions)(nil),      // 16: google.protobuf.EnumOptions
+	(*descriptorpb.EnumValueOptions)(nil), // 17: google.protobuf.EnumValueOptions
+	(*descriptorpb.MessageOptions)(nil),   // 18: google.protobuf.MessageOptions
 }
 var file_annotations_proto_depIdxs = []int32{
 	0,  // 0: es.api.FieldMeta.SearchOptions:type_name -> es.api.SearchOption.Enum
-	3,  // 1: es.api.FieldMeta.Fields:type_name -> es.api.FieldMeta
-	4,  // 2: es.api.FieldMeta.EnumDescription:type_name -> es.api.EnumDescription
-	1,  // 3: es.api.EnumDescription.Enums:type_name -> es.api.EnumMeta
-	3,  // 4: es.api.MessageDescription.Fields:type_name -> es.api.FieldMeta
-	6,  // 5: es.api.allowed_roles:extendee -> google.protobuf.MethodOptions
-	6,  // 6: es.api.cli_cmd:extendee -> google.protobuf.MethodOptions
-	6,  // 7: es.api.refresh_interval:extendee -> google.protobuf.MethodOptions
-	6,  // 8: es.api.scopes:extendee -> google.protobuf.MethodOptions
-	7,  // 9: es.api.search:extendee -> google.protobuf.FieldOptions
-	7,  // 10: es.api.display:extendee -> google.protobuf.FieldOptions
-	7,  // 11: es.api.description:extendee -> google.protobuf.FieldOptions
-	7,  // 12: es.api.required:extendee -> google.protobuf.FieldOptions
-	7,  // 13: es.api.required_or:extendee -> google.protobuf.FieldOptions
-	7,  // 14: es.api.min:extendee -> google.protobuf.FieldOptions
-	7,  // 15: es.api.max:extendee -> google.protobuf.FieldOptions
-	7,  // 16: es.api.min_count:extendee -> google.protobuf.FieldOptions
-	7,  // 17: es.api.max_count:extendee -> google.protobuf.FieldOptions
-	7,  // 18: es.api.alias:extendee -> google.protobuf.FieldOptions
-	8,  // 19: es.api.is_bitmask:extendee -> google.protobuf.EnumOptions
-	9,  // 20: es.api.enum_args:extendee -> google.protobuf.EnumValueOptions
-	9,  // 21: es.api.enum_display:extendee -> google.protobuf.EnumValueOptions
-	9,  // 22: es.api.enum_description:extendee -> google.protobuf.EnumValueOptions
-	9,  // 23: es.api.enum_group:extendee -> google.protobuf.EnumValueOptions
-	9,  // 24: es.api.enum_opts:extendee -> google.protobuf.EnumValueOptions
-	10, // 25: es.api.generate_meta:extendee -> google.protobuf.MessageOptions
-	10, // 26: es.api.message_display:extendee -> google.protobuf.MessageOptions
-	10, // 27: es.api.message_description:extendee -> google.protobuf.MessageOptions
-	10, // 28: es.api.generate_model:extendee -> google.protobuf.MessageOptions
-	29, // [29:29] is the sub-list for method output_type
-	29, // [29:29] is the sub-list for method input_type
-	29, // [29:29] is the sub-list for extension type_name
-	5,  // [5:29] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	4,  // 1: es.api.FieldMeta.Fields:type_name -> es.api.FieldMeta
+	9,  // 2: es.api.FieldMeta.EnumDescription:type_name -> es.api.EnumDescription
+	8,  // 3: es.api.FieldMeta.Range:type_name -> es.api.NumberRange
+	7,  // 4: es.api.FieldMeta.TimeRange:type_name -> es.api.TimeRange
+	5,  // 5: es.api.FieldMeta.Items:type_name -> es.api.ItemRules
+	5,  // 6: es.api.FieldMeta.Keys:type_name -> es.api.ItemRules
+	8,  // 7: es.api.ItemRules.Range:type_name -> es.api.NumberRange
+	6,  // 8: es.api.NumberRange.Min:type_name -> es.api.NumberBound
+	6,  // 9: es.api.NumberRange.Max:type_name -> es.api.NumberBound
+	2,  // 10: es.api.EnumDescription.Enums:type_name -> es.api.EnumMeta
+	4,  // 11: es.api.MessageDescription.Fields:type_name -> es.api.FieldMeta
+	1,  // 12: es.api.MessageDescription.Rules:type_name -> es.api.MessageRule
+	11, // 13: es.api.MessageDescription.Oneofs:type_name -> es.api.OneofMeta
+	12, // 14: es.api.allowed_roles:extendee -> google.protobuf.MethodOptions
+	12, // 15: es.api.cli_cmd:extendee -> google.protobuf.MethodOptions
+	12, // 16: es.api.refresh_interval:extendee -> google.protobuf.MethodOptions
+	12, // 17: es.api.scopes:extendee -> google.protobuf.MethodOptions
+	13, // 18: es.api.validate_requests:extendee -> google.protobuf.ServiceOptions
+	14, // 19: es.api.search:extendee -> google.protobuf.FieldOptions
+	14, // 20: es.api.display:extendee -> google.protobuf.FieldOptions
+	14, // 21: es.api.description:extendee -> google.protobuf.FieldOptions
+	14, // 22: es.api.required:extendee -> google.protobuf.FieldOptions
+	14, // 23: es.api.required_or:extendee -> google.protobuf.FieldOptions
+	14, // 24: es.api.min:extendee -> google.protobuf.FieldOptions
+	14, // 25: es.api.max:extendee -> google.protobuf.FieldOptions
+	14, // 26: es.api.min_count:extendee -> google.protobuf.FieldOptions
+	14, // 27: es.api.max_count:extendee -> google.protobuf.FieldOptions
+	14, // 28: es.api.alias:extendee -> google.protobuf.FieldOptions
+	14, // 29: es.api.pattern:extendee -> google.protobuf.FieldOptions
+	14, // 30: es.api.prefix:extendee -> google.protobuf.FieldOptions
+	14, // 31: es.api.suffix:extendee -> google.protobuf.FieldOptions
+	14, // 32: es.api.format:extendee -> google.protobuf.FieldOptions
+	14, // 33: es.api.in:extendee -> google.protobuf.FieldOptions
+	14, // 34: es.api.not_in:extendee -> google.protobuf.FieldOptions
+	14, // 35: es.api.range:extendee -> google.protobuf.FieldOptions
+	14, // 36: es.api.time_range:extendee -> google.protobuf.FieldOptions
+	14, // 37: es.api.unique:extendee -> google.protobuf.FieldOptions
+	14, // 38: es.api.items:extendee -> google.protobuf.FieldOptions
+	14, // 39: es.api.keys:extendee -> google.protobuf.FieldOptions
+	14, // 40: es.api.sensitive:extendee -> google.protobuf.FieldOptions
+	14, // 41: es.api.reveal_roles:extendee -> google.protobuf.FieldOptions
+	14, // 42: es.api.model_tags:extendee -> google.protobuf.FieldOptions
+	15, // 43: es.api.oneof_required:extendee -> google.protobuf.OneofOptions
+	16, // 44: es.api.is_bitmask:extendee -> google.protobuf.EnumOptions
+	17, // 45: es.api.enum_args:extendee -> google.protobuf.EnumValueOptions
+	17, // 46: es.api.enum_display:extendee -> google.protobuf.EnumValueOptions
+	17, // 47: es.api.enum_description:extendee -> google.protobuf.EnumValueOptions
+	17, // 48: es.api.enum_group:extendee -> google.protobuf.EnumValueOptions
+	17, // 49: es.api.enum_opts:extendee -> google.protobuf.EnumValueOptions
+	18, // 50: es.api.generate_meta:extendee -> google.protobuf.MessageOptions
+	18, // 51: es.api.message_display:extendee -> google.protobuf.MessageOptions
+	18, // 52: es.api.message_description:extendee -> google.protobuf.MessageOptions
+	18, // 53: es.api.generate_model:extendee -> google.protobuf.MessageOptions
+	18, // 54: es.api.rules:extendee -> google.protobuf.MessageOptions
+	8,  // 55: es.api.range:type_name -> es.api.NumberRange
+	7,  // 56: es.api.time_range:type_name -> es.api.TimeRange
+	5,  // 57: es.api.items:type_name -> es.api.ItemRules
+	5,  // 58: es.api.keys:type_name -> es.api.ItemRules
+	1,  // 59: es.api.rules:type_name -> es.api.MessageRule
+	60, // [60:60] is the sub-list for method output_type
+	60, // [60:60] is the sub-list for method input_type
+	55, // [55:60] is the sub-list for extension type_name
+	14, // [14:55] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_annotations_proto_init() }
@@ -1107,14 +2140,19 @@ func file_annotations_proto_init() {
 	if File_annotations_proto != nil {
 		return
 	}
+	file_annotations_proto_msgTypes[5].OneofWrappers = []any{
+		(*NumberBound_Int)(nil),
+		(*NumberBound_Uint)(nil),
+		(*NumberBound_Float)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_annotations_proto_rawDesc), len(file_annotations_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   5,
-			NumExtensions: 24,
+			NumMessages:   11,
+			NumExtensions: 41,
 			NumServices:   0,
 		},
 		GoTypes:           file_annotations_proto_goTypes,
@@ -15,6 +15,7 @@ import (
 	"github.com/olekukonko/tablewriter"
 	"github.com/olekukonko/tablewriter/renderer"
 	"github.com/olekukonko/tablewriter/tw"
+	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/protobuf/proto"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
@@ -165,6 +166,10 @@ func (d *describer) ConvertToMap(msg proto.Message) values.MapAny {
 
 	// Iterate over the fields
 	msgReflect.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if HasFieldBehavior(fd, annotations.FieldBehavior_INPUT_ONLY) {
+			// INPUT_ONLY fields are not included in the output
+			return true
+		}
 		name := string(fd.Name())
 		kind := fd.Kind()
 		var displayName string
@@ -410,14 +415,15 @@ func (d *describer) GetTabularData(msg proto.Message) (*TabularData, error) {
 	msgReflect := msg.ProtoReflect()
 
 	tabularData := &TabularData{}
-	if len(md.Fields) == 0 {
+	fields := filterOutputFields(md.Fields)
+	if len(fields) == 0 {
 		return tabularData, nil
 	}
 
-	// first top level fields
+	// first top level fields, without INPUT_ONLY fields
 	t := &Table{
 		ID:       md.GetDisplayName(),
-		Header:   md.Fields,
+		Header:   fields,
 		RawValue: mpval.Interface(),
 	}
 	rows := d.createRow(msgReflect, t.Header)
@@ -429,7 +435,7 @@ func (d *describer) GetTabularData(msg proto.Message) (*TabularData, error) {
 		mpval = mpval.Elem()
 	}
 
-	for _, field := range md.Fields {
+	for _, field := range fields {
 		// TODO: maps?
 		if field.Type != "[]struct" {
 			continue
@@ -494,6 +500,13 @@ func (d *describer) createRow(rmsg protoreflect.Message, fields []*FieldMeta) *T
 			continue
 		}
 
+		if fd.HasPresence() && !rmsg.Has(fd) {
+			// the field with explicit presence is not set
+			row.Cells = append(row.Cells, "")
+			row.Values = append(row.Values, nil)
+			continue
+		}
+
 		fdv := rmsg.Get(fd)
 		pv := d.colValue(fd, fdv)
 		row.Cells = append(row.Cells, pv)
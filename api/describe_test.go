@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"slices"
 	"testing"
 
 	"github.com/effective-security/protoc-gen-go/api"
@@ -202,6 +203,12 @@ func Test_DocumentMessage(t *testing.T) {
       Type: keyword
     - Field: values
       Type: keyword
+    - Field: priority
+      Type: integer
+      Documentation: priority is for testing proto3 optional fields
+    - Field: timeout
+      Type: flat_object
+      Documentation: timeout is for testing the Duration presence in the models
 
 `
 	checkDoc(e2e.Basic_MessageDescription, "  ", exp1)
@@ -791,3 +798,77 @@ func createTable(w io.Writer) *tablewriter.Table {
 			},
 		))
 }
+
+// inputOnlyAnnotation provides MessageDescription with INPUT_ONLY fields
+type inputOnlyAnnotation struct {
+	*e2e.Annotation
+}
+
+func (inputOnlyAnnotation) GetMessageDescription() *api.MessageDescription {
+	return &api.MessageDescription{
+		Name:     "Annotation",
+		FullName: "e2e.Annotation",
+		Fields: []*api.FieldMeta{
+			{Name: "ID", Type: "string"},
+			{Name: "Name", Type: "string", InputOnly: true},
+			{Name: "Metadata", Type: "[]struct", Fields: []*api.FieldMeta{
+				{Name: "Key", Type: "string"},
+				{Name: "Value", Type: "string", InputOnly: true},
+			}},
+		},
+	}
+}
+
+func Test_InputOnly(t *testing.T) {
+	t.Parallel()
+
+	val := &e2e.Generic{
+		Name:     "test",
+		Data:     []byte("secret"),
+		Messages: []*e2e.Generic_Message{{Name: "msg", Id: "1"}},
+	}
+
+	w := bytes.NewBuffer([]byte{})
+	api.Describe(w, val)
+	assert.Equal(t, `messages:
+    - id: "1"
+      name: msg
+name: test
+`, w.String())
+
+	td, err := api.GetTabularData(inputOnlyAnnotation{Annotation: &e2e.Annotation{
+		ID:       "1",
+		Name:     "secret",
+		Metadata: []*e2e.KVPair{{Key: "k", Value: "secret"}},
+	}})
+	require.NoError(t, err)
+	require.Len(t, td.Tables, 2)
+	require.Len(t, td.Tables[0].Header, 2)
+	assert.Equal(t, []string{"1", "1 items"}, td.Tables[0].Rows[0].Cells)
+	require.Len(t, td.Tables[1].Header, 1)
+	assert.Equal(t, []string{"k"}, td.Tables[1].Rows[0].Cells)
+}
+
+func Test_ExplicitPresence(t *testing.T) {
+	t.Parallel()
+
+	val := &e2e.Basic{Name: "test"}
+	td, err := api.GetTabularData(val)
+	require.NoError(t, err)
+	row := td.Tables[0].Rows[0]
+	idx := slices.IndexFunc(td.Tables[0].Header, func(f *api.FieldMeta) bool { return f.Name == "priority" })
+	require.NotEqual(t, -1, idx)
+	assert.Equal(t, "", row.Cells[idx])
+	assert.Nil(t, row.Values[idx])
+
+	val.Priority = proto.Int32(0)
+	td, err = api.GetTabularData(val)
+	require.NoError(t, err)
+	row = td.Tables[0].Rows[0]
+	assert.Equal(t, "0", row.Cells[idx])
+	assert.Equal(t, int32(0), row.Values[idx])
+
+	w := bytes.NewBuffer([]byte{})
+	api.Describe(w, val)
+	assert.Equal(t, "name: test\npriority: 0\n", w.String())
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/effective-security/porto/pkg/retriable"
+	"github.com/effective-security/porto/xhttp/httperror"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// HTTPRequester is implemented by HTTP clients that support REST methods.
+type HTTPRequester interface {
+	// Request sends the request with HTTP method to the path,
+	// and decodes the response into responseBody.
+	// requestBody can be nil, if the request has no body.
+	Request(ctx context.Context, method, path string, requestBody, responseBody any) (http.Header, int, error)
+}
+
+// HTTPClient extends retriable.PostRequester with Request and PostStream,
+// so NewHTTP...Client and NewREST...Client proxies can call REST
+// and server-streaming methods.
+type HTTPClient struct {
+	retriable.PostRequester
+
+	// Client is the HTTP client for REST and streaming requests,
+	// if not provided, http.DefaultClient is used.
+	// Note that Client.Timeout applies to the entire stream.
+	Client *http.Client
+	// BaseURL is the host URL, for example https://localhost:8080
+	BaseURL string
+	// Header is added to each request
+	Header http.Header
+}
+
+// Request sends the request and decodes the response into responseBody.
+func (c *HTTPClient) Request(ctx context.Context, method, path string, requestBody, responseBody any) (http.Header, int, error) {
+	resp, err := c.do(ctx, method, path, requestBody, "application/json")
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if responseBody != nil {
+		js, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.Header, resp.StatusCode, errors.WithStack(err)
+		}
+		if len(js) > 0 {
+			if err = unmarshalStreamResult(js, responseBody); err != nil {
+				return resp.Header, resp.StatusCode, errors.WithMessage(err, "failed to decode response")
+			}
+		}
+	}
+	return resp.Header, resp.StatusCode, nil
+}
+
+// PostStream sends the request and returns the response body.
+func (c *HTTPClient) PostStream(ctx context.Context, path string, requestBody any) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodPost, path, requestBody, ContentTypeNDJSON)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// do sends the request, and returns the response with 2xx status,
+// otherwise the error from the response body.
+func (c *HTTPClient) do(ctx context.Context, method, path string, requestBody any, accept string) (*http.Response, error) {
+	var body io.Reader
+	if requestBody != nil {
+		js, err := marshalRequestBody(requestBody)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to encode request")
+		}
+		body = bytes.NewReader(js)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.BaseURL, "/")+path, body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", accept)
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		js, _ := io.ReadAll(resp.Body)
+		var verr validationErrorJSON
+		if err = json.Unmarshal(js, &verr); err == nil && len(verr.FieldViolations) > 0 {
+			return nil, &ValidationError{Violations: verr.FieldViolations}
+		}
+		var herr httperror.Error
+		if err = json.Unmarshal(js, &herr); err != nil || herr.Code == "" {
+			return nil, httperror.NewGrpc(codes.Unknown, "unexpected response status: %d", resp.StatusCode)
+		}
+		return nil, &herr
+	}
+	return resp, nil
+}
+
+func marshalRequestBody(v any) ([]byte, error) {
+	switch b := v.(type) {
+	case json.RawMessage:
+		return b, nil
+	case proto.Message:
+		return protojson.Marshal(b)
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,513 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/effective-security/porto/restserver"
+	"github.com/effective-security/porto/xhttp/httperror"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// HTTPRoute is the REST route generated from google.api.http option.
+type HTTPRoute struct {
+	// Method is HTTP method: GET, PUT, POST, PATCH or DELETE
+	Method string
+	// Path is the router path, where {field} variables are
+	// converted to :field parameters
+	Path string
+	// FullMethodName is the gRPC method name
+	FullMethodName string
+	// Handle is the HTTP handler
+	Handle restserver.Handle
+}
+
+// RouteMiddleware wraps the handler of the route,
+// for example to add metrics or rate limits per method.
+type RouteMiddleware func(route HTTPRoute, next restserver.Handle) restserver.Handle
+
+// HTTPRouter registers HTTP handlers, it is implemented by restserver.Router.
+type HTTPRouter interface {
+	Handle(method string, path string, handle restserver.Handle)
+}
+
+// RegisterRoutes registers the routes with the router,
+// the middleware is applied to each route, in the provided order.
+func RegisterRoutes(router HTTPRouter, routes []HTTPRoute, middleware ...RouteMiddleware) {
+	for _, route := range routes {
+		handle := route.Handle
+		for i := len(middleware) - 1; i >= 0; i-- {
+			handle = middleware[i](route, handle)
+		}
+		router.Handle(route.Method, route.Path, handle)
+	}
+}
+
+// PathParams provides values of the path variables,
+// it is implemented by restserver.Params.
+type PathParams interface {
+	ByName(name string) string
+}
+
+// BindHTTPRequest populates req from the HTTP request, according to
+// google.api.http rule:
+//   - body is "*" to decode the entire body into req,
+//     or a name of the field to decode the body into,
+//     or empty if the request has no body;
+//   - pathParams is the list of field paths bound from the path variables;
+//   - the rest of the fields are bound from the query parameters,
+//     unless body is "*".
+func BindHTTPRequest(ctx context.Context, r *http.Request, params PathParams, req proto.Message, body string, pathParams ...string) error {
+	msg := req.ProtoReflect()
+
+	bound := map[string]bool{}
+	if body != "" {
+		if err := bindHTTPBody(r, msg, body); err != nil {
+			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "failed to decode request body: %s", err.Error())
+		}
+		bound[body] = true
+	}
+
+	for _, name := range pathParams {
+		val := params.ByName(name)
+		if val == "" {
+			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "missing path parameter: %s", name)
+		}
+		// the router provides the decoded values,
+		// the catch-all values of {field=**} start with "/"
+		val = strings.TrimPrefix(val, "/")
+		if val == "" {
+			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "missing path parameter: %s", name)
+		}
+		if err := setFieldPath(msg, name, val); err != nil {
+			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "invalid path parameter %s: %s", name, err.Error())
+		}
+		bound[name] = true
+	}
+
+	if body == "*" {
+		return nil
+	}
+	for name, vals := range r.URL.Query() {
+		if bound[name] || bound[strings.Split(name, ".")[0]] {
+			continue
+		}
+		for _, val := range vals {
+			if err := setFieldPath(msg, name, val); err != nil {
+				if err == errFieldNotFound {
+					// ignore unknown query parameters
+					break
+				}
+				return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "invalid query parameter %s: %s", name, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func bindHTTPBody(r *http.Request, msg protoreflect.Message, body string) error {
+	if r.Body == nil {
+		return nil
+	}
+	js, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(js) == 0 {
+		return nil
+	}
+
+	target := msg
+	if body != "*" {
+		fd, parent, err := findFieldPath(msg, body)
+		if err != nil {
+			return err
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			val, err := parseJSONValue(parent, fd, js)
+			if err != nil {
+				return err
+			}
+			parent.Set(fd, val)
+			return nil
+		}
+		target = parent.Mutable(fd).Message()
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(js, target.Interface())
+}
+
+// parseJSONValue decodes the body into the scalar, list or map field,
+// by wrapping it in the JSON object.
+func parseJSONValue(parent protoreflect.Message, fd protoreflect.FieldDescriptor, js []byte) (protoreflect.Value, error) {
+	wrapper := map[string]json.RawMessage{fd.JSONName(): js}
+	wjs, err := json.Marshal(wrapper)
+	if err != nil {
+		return protoreflect.Value{}, err
+	}
+	m := parent.New()
+	if err = protojson.Unmarshal(wjs, m.Interface()); err != nil {
+		return protoreflect.Value{}, err
+	}
+	return m.Get(fd), nil
+}
+
+type fieldError string
+
+func (e fieldError) Error() string { return string(e) }
+
+const (
+	errFieldNotFound = fieldError("field not found")
+	errMapField      = fieldError("map fields are supported only as map.key=value with scalar values")
+)
+
+// findFieldPath returns the descriptor of the field by the dot-separated
+// path, and the message that contains the field.
+// Intermediate messages are created, if not set.
+func findFieldPath(msg protoreflect.Message, path string) (protoreflect.FieldDescriptor, protoreflect.Message, error) {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		// the map keys are specified as Map[key] or Map.key
+		name, _, indexed := strings.Cut(name, "[")
+		fields := msg.Descriptor().Fields()
+		fd := fields.ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = fields.ByJSONName(name)
+		}
+		if fd == nil {
+			return nil, nil, errFieldNotFound
+		}
+		if fd.IsMap() && (indexed || i < len(names)-1) {
+			return nil, nil, errMapField
+		}
+		if indexed {
+			return nil, nil, errFieldNotFound
+		}
+		if i == len(names)-1 {
+			return fd, msg, nil
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return nil, nil, errFieldNotFound
+		}
+		msg = msg.Mutable(fd).Message()
+	}
+	return nil, nil, errFieldNotFound
+}
+
+// setFieldPath sets the value of the field by the dot-separated path,
+// for repeated fields the value is appended,
+// for map fields the entry is set by map.key path.
+func setFieldPath(msg protoreflect.Message, path, val string) error {
+	fd, parent, err := findFieldPath(msg, path)
+	if err == errMapField {
+		return setMapEntry(msg, path, val)
+	}
+	if err != nil {
+		return err
+	}
+	if fd.IsMap() {
+		return errMapField
+	}
+	v, err := parseFieldValue(parent, fd, val)
+	if err != nil {
+		return err
+	}
+	if fd.IsList() {
+		parent.Mutable(fd).List().Append(v)
+		return nil
+	}
+	parent.Set(fd, v)
+	return nil
+}
+
+// setMapEntry sets the entry of the map field by map.key path,
+// the key is the rest of the path after the map field, and may contain dots.
+func setMapEntry(msg protoreflect.Message, path, val string) error {
+	names := strings.Split(path, ".")
+	for i := 1; i < len(names); i++ {
+		fd, parent, err := findFieldPath(msg, strings.Join(names[:i], "."))
+		if err != nil {
+			return err
+		}
+		if !fd.IsMap() {
+			continue
+		}
+		if fd.MapValue().Message() != nil {
+			return errMapField
+		}
+		key, err := parseFieldValue(parent, fd.MapKey(), strings.Join(names[i:], "."))
+		if err != nil {
+			return err
+		}
+		v, err := parseFieldValue(parent, fd.MapValue(), val)
+		if err != nil {
+			return err
+		}
+		parent.Mutable(fd).Map().Set(key.MapKey(), v)
+		return nil
+	}
+	return errMapField
+}
+
+func parseFieldValue(parent protoreflect.Message, fd protoreflect.FieldDescriptor, val string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(val), nil
+	case protoreflect.BytesKind:
+		b, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			b, err = base64.URLEncoding.DecodeString(val)
+		}
+		if err != nil {
+			return protoreflect.Value{}, fieldError("invalid base64 value")
+		}
+		return protoreflect.ValueOfBytes(b), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return protoreflect.Value{}, fieldError("invalid bool value")
+		}
+		return protoreflect.ValueOfBool(b), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(val, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fieldError("invalid int32 value")
+		}
+		return protoreflect.ValueOfInt32(int32(n)), nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return protoreflect.Value{}, fieldError("invalid int64 value")
+		}
+		return protoreflect.ValueOfInt64(n), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(val, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fieldError("invalid uint32 value")
+		}
+		return protoreflect.ValueOfUint32(uint32(n)), nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			return protoreflect.Value{}, fieldError("invalid uint64 value")
+		}
+		return protoreflect.ValueOfUint64(n), nil
+	case protoreflect.FloatKind:
+		n, err := strconv.ParseFloat(val, 32)
+		if err != nil {
+			return protoreflect.Value{}, fieldError("invalid float value")
+		}
+		return protoreflect.ValueOfFloat32(float32(n)), nil
+	case protoreflect.DoubleKind:
+		n, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return protoreflect.Value{}, fieldError("invalid double value")
+		}
+		return protoreflect.ValueOfFloat64(n), nil
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(val)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		n, err := strconv.ParseInt(val, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fieldError("invalid enum value")
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		// well-known types, like Timestamp or Duration, are decoded from JSON string
+		js, _ := json.Marshal(val)
+		var m protoreflect.Message
+		if fd.IsList() {
+			m = parent.Mutable(fd).List().NewElement().Message()
+		} else {
+			m = parent.NewField(fd).Message()
+		}
+		if err := protojson.Unmarshal(js, m.Interface()); err != nil {
+			// wrappers of numbers and bools are not quoted
+			if err = protojson.Unmarshal([]byte(val), m.Interface()); err != nil {
+				return protoreflect.Value{}, fieldError("invalid " + string(fd.Message().FullName()) + " value")
+			}
+		}
+		return protoreflect.ValueOfMessage(m), nil
+	}
+	return protoreflect.Value{}, fieldError("unsupported field type: " + fd.Kind().String())
+}
+
+var pathVarRegex = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?\}`)
+
+// HTTPRequestParams returns the path and the body of the REST request,
+// according to google.api.http rule:
+//   - the variables of the path template are populated from req;
+//   - body is "*" to return req as the body, or the field to return,
+//     or empty if the request has no body;
+//   - the rest of the fields are added as the query parameters,
+//     unless body is "*".
+func HTTPRequestParams(req proto.Message, tpl, body string) (string, any, error) {
+	msg := proto.Clone(req).ProtoReflect()
+
+	var err error
+	path := pathVarRegex.ReplaceAllStringFunc(tpl, func(v string) string {
+		m := pathVarRegex.FindStringSubmatch(v)
+		name, pattern := m[1], strings.TrimPrefix(m[2], "=")
+
+		fd, parent, ferr := findFieldPath(msg, name)
+		if ferr != nil || fd.IsList() || fd.IsMap() {
+			err = httperror.NewGrpc(codes.InvalidArgument, "invalid path parameter: %s", name)
+			return v
+		}
+		val := formatFieldValue(fd, parent.Get(fd))
+		if val == "" {
+			err = httperror.NewGrpc(codes.InvalidArgument, "missing path parameter: %s", name)
+			return v
+		}
+		parent.Clear(fd)
+
+		if pattern == "**" {
+			segments := strings.Split(val, "/")
+			for i := range segments {
+				segments[i] = url.PathEscape(segments[i])
+			}
+			return strings.Join(segments, "/")
+		}
+		return url.PathEscape(val)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var reqBody any
+	switch body {
+	case "":
+	case "*":
+		return path, req, nil
+	default:
+		fd, parent, ferr := findFieldPath(msg, body)
+		if ferr != nil {
+			return "", nil, httperror.NewGrpc(codes.InvalidArgument, "invalid body field: %s", body)
+		}
+		tmp := parent.New()
+		tmp.Set(fd, parent.Get(fd))
+		js, err := protojson.Marshal(tmp.Interface())
+		if err != nil {
+			return "", nil, errors.WithMessage(err, "failed to encode request body")
+		}
+		var fields map[string]json.RawMessage
+		if err = json.Unmarshal(js, &fields); err != nil {
+			return "", nil, errors.WithMessage(err, "failed to encode request body")
+		}
+		reqBody = fields[fd.JSONName()]
+		if reqBody == nil {
+			reqBody = json.RawMessage("{}")
+		}
+		parent.Clear(fd)
+	}
+
+	query, err := queryValues(msg.Interface())
+	if err != nil {
+		return "", nil, err
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return path, reqBody, nil
+}
+
+// queryValues returns the populated fields of the message as query parameters,
+// nested messages and maps are flattened with dot-separated names.
+func queryValues(msg proto.Message) (url.Values, error) {
+	if name := queryMapField(msg.ProtoReflect()); name != "" {
+		return nil, httperror.NewGrpc(codes.InvalidArgument, "map field %s with message values can not be sent as query parameters", name)
+	}
+	js, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to encode query parameters")
+	}
+	var fields map[string]any
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	if err = dec.Decode(&fields); err != nil {
+		return nil, errors.WithMessage(err, "failed to encode query parameters")
+	}
+
+	res := url.Values{}
+	flattenQuery(res, "", fields)
+	return res, nil
+}
+
+// queryMapField returns the name of the populated map field with message values,
+// the server binds only the scalar map values from map.key query parameters.
+func queryMapField(msg protoreflect.Message) string {
+	var res string
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				res = fd.JSONName()
+			}
+		case fd.IsList() || fd.Message() == nil:
+		default:
+			if name := queryMapField(v.Message()); name != "" {
+				res = fd.JSONName() + "." + name
+			}
+		}
+		return res == ""
+	})
+	return res
+}
+
+func flattenQuery(res url.Values, prefix string, v any) {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, fv := range val {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			flattenQuery(res, k, fv)
+		}
+	case []any:
+		for _, item := range val {
+			flattenQuery(res, prefix, item)
+		}
+	case nil:
+	default:
+		res.Add(prefix, fmt.Sprint(val))
+	}
+}
+
+// formatFieldValue returns the value of the singular field as string.
+func formatFieldValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return v.String()
+	case protoreflect.BytesKind:
+		return base64.URLEncoding.EncodeToString(v.Bytes())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		js, err := protojson.Marshal(v.Message().Interface())
+		if err != nil {
+			return ""
+		}
+		var s string
+		if json.Unmarshal(js, &s) == nil {
+			return s
+		}
+		return string(js)
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,338 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/effective-security/porto/restserver"
+	"github.com/effective-security/protoc-gen-go/api"
+	"github.com/effective-security/protoc-gen-go/e2e"
+	"github.com/effective-security/protoc-gen-go/e2e/httppb"
+	"github.com/effective-security/protoc-gen-go/e2e/proxypb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type pathParams map[string]string
+
+func (p pathParams) ByName(name string) string { return p[name] }
+
+func TestBindHTTPRequest(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("query", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/v1/annotations?Name=test&AssetIDs=1&AssetIDs=2&unknown=1", nil)
+		req := &e2e.ListAnnotationsRequest{}
+		require.NoError(t, api.BindHTTPRequest(ctx, r, pathParams{}, req, ""))
+		assert.Equal(t, "test", req.Name)
+		assert.Equal(t, []string{"1", "2"}, req.AssetIDs)
+	})
+
+	t.Run("path", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/v1/annotations/123?Type=Foo&Int32Value=3&Types=1&Types=Bar&Basic.Name=nested", nil)
+		req := &e2e.Annotation{}
+		require.NoError(t, api.BindHTTPRequest(ctx, r, pathParams{"ID": "123"}, req, "", "ID"))
+		assert.Equal(t, "123", req.ID)
+		assert.Equal(t, e2e.AnnotationType_Foo, req.Type)
+		assert.Equal(t, int32(3), req.Int32Value)
+		assert.Equal(t, []e2e.AnnotationType_Enum{e2e.AnnotationType_Bar, e2e.AnnotationType_Bar}, req.Types)
+		assert.Equal(t, "nested", req.Basic.GetName())
+
+		err := api.BindHTTPRequest(ctx, r, pathParams{}, &e2e.Annotation{}, "", "ID")
+		assert.EqualError(t, err, "bad_request: missing path parameter: ID")
+
+		r = httptest.NewRequest(http.MethodGet, "/v1/annotations/123?Int32Value=x", nil)
+		err = api.BindHTTPRequest(ctx, r, pathParams{"ID": "123"}, &e2e.Annotation{}, "", "ID")
+		assert.EqualError(t, err, "bad_request: invalid query parameter Int32Value: invalid int32 value")
+	})
+
+	t.Run("path_values", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/v1/annotations/a/b", nil)
+		req := &e2e.Annotation{}
+		// the router value of {ID=**} starts with "/"
+		require.NoError(t, api.BindHTTPRequest(ctx, r, pathParams{"ID": "/a/b"}, req, "", "ID"))
+		assert.Equal(t, "a/b", req.ID)
+
+		err := api.BindHTTPRequest(ctx, r, pathParams{"ID": "/"}, &e2e.Annotation{}, "", "ID")
+		assert.EqualError(t, err, "bad_request: missing path parameter: ID")
+
+		// the router provides the decoded values, that are not decoded again
+		r = httptest.NewRequest(http.MethodGet, "/v1/annotations/100%2525", nil)
+		req = &e2e.Annotation{}
+		require.NoError(t, api.BindHTTPRequest(ctx, r, pathParams{"ID": "100%25"}, req, "", "ID"))
+		assert.Equal(t, "100%25", req.ID)
+
+		req = &e2e.Annotation{}
+		require.NoError(t, api.BindHTTPRequest(ctx, r, pathParams{"ID": "a%2Fb"}, req, "", "ID"))
+		assert.Equal(t, "a%2Fb", req.ID)
+	})
+
+	t.Run("map_query", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/v1/annotations/123?Map.k=v&Map.a.b=c&Basic.map.k=v", nil)
+		req := &e2e.Annotation{}
+		require.NoError(t, api.BindHTTPRequest(ctx, r, pathParams{"ID": "123"}, req, "", "ID"))
+		assert.Equal(t, map[string]string{"k": "v", "a.b": "c"}, req.Map)
+		assert.Equal(t, map[string]string{"k": "v"}, req.Basic.GetMap())
+
+		r = httptest.NewRequest(http.MethodGet, "/v1/generic?map1.k=S3Bucket", nil)
+		generic := &e2e.Generic{}
+		require.NoError(t, api.BindHTTPRequest(ctx, r, nil, generic, ""))
+		assert.Equal(t, map[string]e2e.ResourceType_Enum{"k": e2e.ResourceType_S3Bucket}, generic.Map1)
+
+		for query, msg := range map[string]proto.Message{
+			"Map=v":         &e2e.Annotation{},
+			"Map%5Bk%5D=v":  &e2e.Annotation{},
+			"map2.k.name=v": &e2e.Generic{},
+			"map1.k=Bogus":  &e2e.Generic{},
+		} {
+			r := httptest.NewRequest(http.MethodGet, "/v1/test?"+query, nil)
+			err := api.BindHTTPRequest(ctx, r, nil, msg, "")
+			require.Error(t, err, query)
+			assert.Contains(t, err.Error(), "bad_request: invalid query parameter ", query)
+		}
+		r = httptest.NewRequest(http.MethodGet, "/v1/test?map2.k.name=v", nil)
+		err := api.BindHTTPRequest(ctx, r, nil, &e2e.Generic{}, "")
+		assert.EqualError(t, err, "bad_request: invalid query parameter map2.k.name: map fields are supported only as map.key=value with scalar values")
+	})
+
+	t.Run("body", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPut, "/v1/annotations/123?Name=ignored", strings.NewReader(`{"Name":"body","Int64Value":"5"}`))
+		req := &e2e.Annotation{}
+		require.NoError(t, api.BindHTTPRequest(ctx, r, pathParams{"ID": "123"}, req, "*", "ID"))
+		assert.Equal(t, "123", req.ID)
+		assert.Equal(t, "body", req.Name)
+		assert.Equal(t, int64(5), req.Int64Value)
+
+		r = httptest.NewRequest(http.MethodPatch, "/v1/annotations/123?Name=query", strings.NewReader(`{"Name":"basic"}`))
+		req = &e2e.Annotation{}
+		require.NoError(t, api.BindHTTPRequest(ctx, r, pathParams{"ID": "123"}, req, "Basic", "ID"))
+		assert.Equal(t, "query", req.Name)
+		assert.Equal(t, "basic", req.Basic.GetName())
+
+		r = httptest.NewRequest(http.MethodPatch, "/v1/annotations/123", strings.NewReader(`["a","b"]`))
+		req = &e2e.Annotation{}
+		require.NoError(t, api.BindHTTPRequest(ctx, r, pathParams{}, req, "Strings"))
+		assert.Equal(t, []string{"a", "b"}, req.Strings)
+
+		r = httptest.NewRequest(http.MethodPut, "/v1/annotations/123", strings.NewReader(`{`))
+		err := api.BindHTTPRequest(ctx, r, pathParams{}, &e2e.Annotation{}, "*")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "bad_request: failed to decode request body")
+	})
+}
+
+type statusServer struct {
+	e2e.StatusServer
+}
+
+func (s *statusServer) Version(_ context.Context, _ *emptypb.Empty) (*e2e.ServerVersion, error) {
+	return &e2e.ServerVersion{Build: "1.2.3"}, nil
+}
+
+func TestStatusHTTPRoutes(t *testing.T) {
+	routes := httppb.GetStatusHTTPRoutes(&statusServer{}, nil)
+	require.Len(t, routes, 5)
+	assert.Equal(t, http.MethodGet, routes[0].Method)
+	assert.Equal(t, "/v1/status/version", routes[0].Path)
+	assert.Equal(t, e2e.Status_Version_FullMethodName, routes[0].FullMethodName)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/v1/status/version", nil)
+	routes[0].Handle(w, r, nil)
+	assert.Contains(t, w.Body.String(), "1.2.3")
+}
+
+func TestHTTPRequestParams(t *testing.T) {
+	req := &e2e.Annotation{
+		ID:         "a/1",
+		Name:       "test",
+		Type:       e2e.AnnotationType_Foo,
+		Int64Value: 5,
+		Strings:    []string{"a", "b"},
+		Basic:      &e2e.Basic{Name: "basic"},
+	}
+
+	path, body, err := api.HTTPRequestParams(req, "/v1/annotations/{ID}", "")
+	require.NoError(t, err)
+	assert.Nil(t, body)
+	assert.Equal(t, "/v1/annotations/a%2F1?Basic.Name=basic&Int64Value=5&Name=test&Strings=a&Strings=b&Type=Foo", path)
+
+	// the request is bound back by the server
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	bound := &e2e.Annotation{}
+	require.NoError(t, api.BindHTTPRequest(context.Background(), r, pathParams{"ID": "a/1"}, bound, "", "ID"))
+	assert.Equal(t, req.String(), bound.String())
+
+	path, body, err = api.HTTPRequestParams(req, "/v1/annotations/{ID=**}", "*")
+	require.NoError(t, err)
+	assert.Equal(t, "/v1/annotations/a/1", path)
+	assert.Equal(t, req, body)
+
+	path, body, err = api.HTTPRequestParams(req, "/v1/annotations/{ID}", "Basic")
+	require.NoError(t, err)
+	assert.Equal(t, "/v1/annotations/a%2F1?Int64Value=5&Name=test&Strings=a&Strings=b&Type=Foo", path)
+	assert.JSONEq(t, `{"Name":"basic"}`, string(body.(json.RawMessage)))
+
+	_, _, err = api.HTTPRequestParams(&e2e.Annotation{}, "/v1/annotations/{ID}", "")
+	assert.EqualError(t, err, "bad_request: missing path parameter: ID")
+	_, _, err = api.HTTPRequestParams(req, "/v1/annotations/{Unknown}", "")
+	assert.EqualError(t, err, "bad_request: invalid path parameter: Unknown")
+}
+
+func TestRESTClient(t *testing.T) {
+	mux := http.NewServeMux()
+	for _, route := range httppb.GetStatusHTTPRoutes(&statusServer{}, nil) {
+		mux.HandleFunc(route.Method+" "+route.Path, func(w http.ResponseWriter, r *http.Request) {
+			route.Handle(w, r, nil)
+		})
+	}
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	client := proxypb.NewRESTStatusClient(&api.HTTPClient{BaseURL: ts.URL})
+	res, err := client.Version(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", res.Build)
+}
+
+type echoServer struct {
+	e2e.E2EServer
+}
+
+func (s *echoServer) Hello(_ context.Context, req *e2e.Basic) (*e2e.Basic, error) {
+	return req, nil
+}
+
+func TestRESTClientMapQuery(t *testing.T) {
+	mux := http.NewServeMux()
+	for _, route := range httppb.GetE2EHTTPRoutes(&echoServer{}, nil) {
+		mux.HandleFunc(route.Method+" "+route.Path, func(w http.ResponseWriter, r *http.Request) {
+			route.Handle(w, r, nil)
+		})
+	}
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	// the map entries are sent as map.key query parameters of GET /v1/hello
+	req := &e2e.Basic{
+		Name:   "map query",
+		Values: []string{"v1"},
+		Map:    map[string]string{"key_1": "value 1", "key_2": "a=b&c"},
+	}
+	client := proxypb.NewRESTE2EClient(&api.HTTPClient{BaseURL: ts.URL})
+	res, err := client.Hello(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(req, res), "%v", res)
+
+	// the map keys with dots are bound and validated by the server
+	req.Map = map[string]string{"Key.1": "value"}
+	_, err = client.Hello(context.Background(), req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "map.keys[Key.1]: must match pattern")
+
+	// the map values of messages are rejected before sending
+	_, _, err = api.HTTPRequestParams(&e2e.Generic{Map2: map[string]*e2e.Generic_Message{"k": {Name: "n"}}}, "/v1/generic", "")
+	assert.EqualError(t, err, "bad_request: map field map2 with message values can not be sent as query parameters")
+}
+
+type testRouter struct {
+	handles map[string]restserver.Handle
+}
+
+func (r *testRouter) Handle(method string, path string, handle restserver.Handle) {
+	r.handles[method+" "+path] = handle
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	router := &testRouter{handles: map[string]restserver.Handle{}}
+
+	var called []string
+	mw := func(name string) api.RouteMiddleware {
+		return func(route api.HTTPRoute, next restserver.Handle) restserver.Handle {
+			return func(w http.ResponseWriter, r *http.Request, p restserver.Params) {
+				called = append(called, name+":"+route.FullMethodName)
+				next(w, r, p)
+			}
+		}
+	}
+
+	srv := &statusServer{}
+	api.RegisterRoutes(router, httppb.GetStatusHTTPMethods(srv, nil), mw("1"), mw("2"))
+	api.RegisterRoutes(router, httppb.GetStatusHTTPRoutes(srv, nil), mw("1"), mw("2"))
+	assert.Len(t, router.handles, 10)
+
+	handle := router.handles["POST "+e2e.Status_Version_FullMethodName]
+	require.NotNil(t, handle)
+	w := httptest.NewRecorder()
+	handle(w, httptest.NewRequest(http.MethodPost, e2e.Status_Version_FullMethodName, strings.NewReader("{}")), nil)
+	assert.Contains(t, w.Body.String(), "1.2.3")
+	assert.Equal(t, []string{"1:" + e2e.Status_Version_FullMethodName, "2:" + e2e.Status_Version_FullMethodName}, called)
+
+	handle = router.handles["GET /v1/status/version"]
+	require.NotNil(t, handle)
+	w = httptest.NewRecorder()
+	handle(w, httptest.NewRequest(http.MethodGet, "/v1/status/version", nil), nil)
+	assert.Contains(t, w.Body.String(), "1.2.3")
+}
+
+func TestHTTPHandlerValidation(t *testing.T) {
+	handler := httppb.GetE2EHTTPHandler(&streamServer{}, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, e2e.E2E_GetAnnotation_FullMethodName, strings.NewReader(`{"ID":"1"}`))
+	handler(w, r, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "ID: minimum length is 9")
+
+	routes := httppb.GetE2EHTTPMethods(&streamServer{}, nil)
+	for _, route := range routes {
+		if route.FullMethodName == e2e.E2E_GetAnnotation_FullMethodName {
+			w = httptest.NewRecorder()
+			r = httptest.NewRequest(http.MethodPost, route.Path, strings.NewReader(`{}`))
+			route.Handle(w, r, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "ID is required")
+		}
+	}
+
+	type errorBody struct {
+		Code            string                `json:"code"`
+		FieldViolations []*api.FieldViolation `json:"field_violations"`
+	}
+	violatedFields := func(t *testing.T, w *httptest.ResponseRecorder) []string {
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var body errorBody
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "bad_request", body.Code)
+		var fields []string
+		for _, fv := range body.FieldViolations {
+			fields = append(fields, fv.Field)
+		}
+		return fields
+	}
+
+	// all violations are returned with field_violations
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, e2e.E2E_Hello_FullMethodName, strings.NewReader(`{"Name":"short"}`))
+	handler(w, r, nil)
+	assert.Len(t, violatedFields(t, w), 3)
+
+	for _, route := range routes {
+		if route.FullMethodName != e2e.E2E_UpdateAnnotation_FullMethodName {
+			continue
+		}
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodPost, route.Path, strings.NewReader(`{"ID":"1","Name":"n","Int32Value":1}`))
+		route.Handle(w, r, nil)
+		fields := violatedFields(t, w)
+		assert.Contains(t, fields, "ID")
+		assert.Contains(t, fields, "Name")
+		assert.Contains(t, fields, "Int32Value")
+	}
+}
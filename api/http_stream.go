@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+	"github.com/effective-security/porto/xhttp/httperror"
+	"github.com/effective-security/xlog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ContentTypeNDJSON is the content type of the HTTP streaming responses,
+// each line is a JSON object with `result` or `error` field.
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// streamFrame is a single line of the HTTP stream,
+// the format is compatible with grpc-gateway.
+type streamFrame struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+// StreamRequester is implemented by HTTP clients that support
+// server-streaming responses.
+type StreamRequester interface {
+	// PostStream sends the request and returns the response body,
+	// the caller must close it.
+	PostStream(ctx context.Context, path string, requestBody any) (io.ReadCloser, error)
+}
+
+// PostStream calls the server-streaming method, and passes
+// each received message to send.
+func PostStream[T any](ctx context.Context, client StreamRequester, path string, req any, send func(*T) error) error {
+	body, err := client.PostStream(ctx, path, req)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+	for {
+		var frame streamFrame
+		if err = dec.Decode(&frame); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errors.WithMessage(err, "failed to decode stream")
+		}
+		if len(frame.Error) > 0 {
+			return decodeStreamError(frame.Error)
+		}
+
+		msg := new(T)
+		if err = unmarshalStreamResult(frame.Result, msg); err != nil {
+			return errors.WithMessage(err, "failed to decode stream message")
+		}
+		if err = send(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// ServeHTTPStream runs the server-streaming handler, and writes
+// each sent message as a line of ContentTypeNDJSON response.
+// If the handler fails before the first message, the error is written
+// as a regular JSON response, otherwise as the last line of the stream.
+func ServeHTTPStream[T any](ctx context.Context, w http.ResponseWriter, r *http.Request, handler func(grpc.ServerStreamingServer[T]) error) {
+	stream := &httpServerStream[T]{
+		DummyServerStream: DummyServerStream{Ctx: ctx},
+		w:                 w,
+	}
+	err := handler(stream)
+	if err != nil {
+		if !stream.started {
+			WriteHTTPError(w, r, err)
+			return
+		}
+		stream.writeError(err)
+		return
+	}
+	if !stream.started {
+		stream.start()
+	}
+}
+
+// httpServerStream writes messages to HTTP response.
+type httpServerStream[T any] struct {
+	DummyServerStream
+	w       http.ResponseWriter
+	started bool
+}
+
+func (s *httpServerStream[T]) start() {
+	s.started = true
+	s.w.Header().Set("Content-Type", ContentTypeNDJSON)
+	s.w.WriteHeader(http.StatusOK)
+}
+
+// Send writes the message and flushes the response.
+func (s *httpServerStream[T]) Send(m *T) error {
+	if err := s.Ctx.Err(); err != nil {
+		return err
+	}
+	js, err := marshalStreamResult(m)
+	if err != nil {
+		return errors.WithMessage(err, "failed to encode stream message")
+	}
+	return s.writeFrame(streamFrame{Result: js})
+}
+
+// SendMsg sends the message, m must be *T.
+func (s *httpServerStream[T]) SendMsg(m any) error {
+	msg, ok := m.(*T)
+	if !ok {
+		return errors.Errorf("unexpected message type %T", m)
+	}
+	return s.Send(msg)
+}
+
+func (s *httpServerStream[T]) writeError(err error) {
+	js, merr := protojson.Marshal(status.Convert(err).Proto())
+	if merr != nil {
+		logger.ContextKV(s.Ctx, xlog.ERROR,
+			"reason", "encode_stream_error",
+			"err", merr,
+		)
+		return
+	}
+	_ = s.writeFrame(streamFrame{Error: js})
+}
+
+func (s *httpServerStream[T]) writeFrame(frame streamFrame) error {
+	if !s.started {
+		s.start()
+	}
+	js, err := json.Marshal(frame)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err = s.w.Write(append(js, '\n')); err != nil {
+		return errors.WithStack(err)
+	}
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+func marshalStreamResult(m any) ([]byte, error) {
+	if pm, ok := m.(proto.Message); ok {
+		return protojson.Marshal(pm)
+	}
+	return json.Marshal(m)
+}
+
+func unmarshalStreamResult(js []byte, m any) error {
+	if pm, ok := m.(proto.Message); ok {
+		return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(js, pm)
+	}
+	return json.Unmarshal(js, m)
+}
+
+func decodeStreamError(js []byte) error {
+	st := status.New(codes.Unknown, "").Proto()
+	if err := protojson.Unmarshal(js, st); err != nil {
+		return httperror.NewGrpc(codes.Unknown, "failed to decode stream error: %s", string(js))
+	}
+	return httperror.NewFromPb(status.ErrorProto(st))
+}
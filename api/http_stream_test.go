@@ -0,0 +1,83 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/effective-security/protoc-gen-go/api"
+	"github.com/effective-security/protoc-gen-go/e2e"
+	"github.com/effective-security/protoc-gen-go/e2e/httppb"
+	"github.com/effective-security/protoc-gen-go/e2e/proxypb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHTTPStream(t *testing.T) {
+	srv := &streamServer{}
+	handler := httppb.GetE2EHTTPHandler(srv, nil)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r, nil)
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	client := proxypb.NewHTTPE2EClient(&api.HTTPClient{BaseURL: ts.URL})
+
+	recv := func(req *e2e.Basic) ([]string, error) {
+		msgs := make(chan *e2e.Basic, 10)
+		err := client.HelloStream(req, &api.ServerStreamAdapter[e2e.Basic]{
+			DummyServerStream: api.DummyServerStream{Ctx: ctx},
+			Msgs:              msgs,
+		})
+		close(msgs)
+		var names []string
+		for m := range msgs {
+			names = append(names, m.Name)
+		}
+		return names, err
+	}
+
+	kv := map[string]string{"k": "v"}
+	names, err := recv(&e2e.Basic{Name: "streamer", Map: kv, Values: []string{"1", "2", "3"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, names)
+
+	// E2E service validates requests and returns all violations
+	names, err = recv(&e2e.Basic{})
+	assert.EqualError(t, err, "bad_request: map: minimum count is 1; name: minimum length is 8; values: minimum count is 1")
+	assert.Empty(t, names)
+
+	// the error after the first message is returned as the last line
+	srv.err = errors.New("failed")
+	names, err = recv(&e2e.Basic{Name: "streamer", Map: kv, Values: []string{"1"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed")
+	assert.Equal(t, []string{"1"}, names)
+
+	t.Run("content_type", func(t *testing.T) {
+		srv := &streamServer{}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, e2e.E2E_HelloStream_FullMethodName, nil)
+		api.ServeHTTPStream(ctx, w, r, func(stream grpc.ServerStreamingServer[e2e.Basic]) error {
+			return srv.HelloStream(&e2e.Basic{Values: []string{"1"}}, stream)
+		})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, api.ContentTypeNDJSON, w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"result":{"Name":"1"}}`, w.Body.String())
+		assert.True(t, w.Flushed)
+	})
+
+	t.Run("unimplemented", func(t *testing.T) {
+		client := proxypb.NewHTTPE2EClient(nil)
+		err := client.HelloStream(&e2e.Basic{}, &api.ServerStreamAdapter[e2e.Basic]{
+			DummyServerStream: api.DummyServerStream{Ctx: ctx},
+		})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
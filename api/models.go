@@ -0,0 +1,22 @@
+package api
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// DurationModel returns the model of the Duration message.
+// Unlike AsDuration, the error is returned instead of the saturated value,
+// if the Duration is invalid, or out of the time.Duration range.
+func DurationModel(x *durationpb.Duration) (*time.Duration, error) {
+	if err := x.CheckValid(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	d := x.AsDuration()
+	if back := durationpb.New(d); back.Seconds != x.Seconds || back.Nanos != x.Nanos {
+		return nil, errors.Errorf("duration out of range: %ds", x.Seconds)
+	}
+	return &d, nil
+}
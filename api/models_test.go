@@ -0,0 +1,31 @@
+package api_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/effective-security/protoc-gen-go/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestDurationModel(t *testing.T) {
+	d, err := api.DurationModel(&durationpb.Duration{})
+	require.NoError(t, err)
+	require.NotNil(t, d)
+	assert.Equal(t, time.Duration(0), *d)
+
+	d, err = api.DurationModel(durationpb.New(-90 * time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, -90*time.Second, *d)
+
+	// AsDuration saturates the value
+	_, err = api.DurationModel(&durationpb.Duration{Seconds: 2 * (math.MaxInt64 / int64(time.Second))})
+	assert.EqualError(t, err, "duration out of range: 18446744072s")
+
+	// the signs of seconds and nanos do not match
+	_, err = api.DurationModel(&durationpb.Duration{Seconds: 1, Nanos: -1})
+	assert.Error(t, err)
+}
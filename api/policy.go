@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"slices"
+
+	"github.com/effective-security/porto/xhttp/httperror"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// MethodPolicy provides the access policy of RPC method,
+// it is implemented by MethodInfo generated by protoc-gen-go-allocator.
+type MethodPolicy interface {
+	GetAllowedRoles() []string
+	GetScopes() []string
+}
+
+// MethodPolicyFunc returns MethodPolicy for the full method name,
+// or nil if the method is not found, for example pb.GetMethodPolicy.
+type MethodPolicyFunc func(fullMethod string) MethodPolicy
+
+// Authorizer checks access to RPC method, policy is nil if the method is not found.
+// req is nil for streaming methods, as the access is checked before the
+// messages are received.
+type Authorizer func(ctx context.Context, fullMethod string, policy MethodPolicy, req any) error
+
+// CallerFunc returns roles and scopes of the caller
+type CallerFunc func(ctx context.Context) (roles []string, scopes []string, err error)
+
+// NewRoleAuthorizer returns Authorizer, that allows the call if the caller has
+// one of es.api.allowed_roles, and one of es.api.scopes of the method.
+// Methods without roles or scopes are allowed for any caller,
+// and methods not found are denied.
+func NewRoleAuthorizer(caller CallerFunc) Authorizer {
+	return func(ctx context.Context, fullMethod string, policy MethodPolicy, _ any) error {
+		if policy == nil {
+			return httperror.NewGrpcFromCtx(ctx, codes.PermissionDenied, "method not allowed: %s", fullMethod)
+		}
+		allowedRoles := policy.GetAllowedRoles()
+		allowedScopes := policy.GetScopes()
+		if len(allowedRoles) == 0 && len(allowedScopes) == 0 {
+			return nil
+		}
+
+		roles, scopes, err := caller(ctx)
+		if err != nil {
+			return err
+		}
+		if len(allowedRoles) > 0 && !containsAny(allowedRoles, roles) {
+			return httperror.NewGrpcFromCtx(ctx, codes.PermissionDenied, "the caller role is not allowed: %s", fullMethod)
+		}
+		if len(allowedScopes) > 0 && !containsAny(allowedScopes, scopes) {
+			return httperror.NewGrpcFromCtx(ctx, codes.PermissionDenied, "the caller scope is not allowed: %s", fullMethod)
+		}
+		return nil
+	}
+}
+
+func containsAny(allowed, values []string) bool {
+	for _, v := range values {
+		if slices.Contains(allowed, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServerPolicy enforces the access policy and request validation
+// for gRPC and HTTP servers.
+type ServerPolicy struct {
+	// Policy returns the policy for the method, for example pb.GetMethodPolicy
+	Policy MethodPolicyFunc
+	// Authorizer checks access to the method,
+	// if not provided, the access is not checked
+	Authorizer Authorizer
+	// Validate specifies to validate requests, see ValidateMessage,
+	// all violations are returned with ValidationError, as by HTTP handlers
+	Validate bool
+	// ValidateResponses specifies to validate responses, to assert
+	// the server invariants, for example in debug builds.
+	// The violation is returned with Internal code.
+	ValidateResponses bool
+	// Redact specifies to redact responses, see RedactMessage
+	Redact bool
+	// Caller returns the roles of the caller, that are allowed to see
+	// the sensitive fields, if not provided or failed, all sensitive fields
+	// are redacted
+	Caller CallerFunc
+}
+
+// Check authorizes and validates the request.
+func (p *ServerPolicy) Check(ctx context.Context, fullMethod string, req any) error {
+	if err := p.authorize(ctx, fullMethod, req); err != nil {
+		return err
+	}
+	if p.Validate {
+		return ValidateMessage(WithAllViolations(ctx), req)
+	}
+	return nil
+}
+
+// CheckResponse validates and redacts the response,
+// it returns the response to send.
+func (p *ServerPolicy) CheckResponse(ctx context.Context, fullMethod string, res any) (any, error) {
+	if p.ValidateResponses {
+		if err := ValidateMessage(WithResponseValidation(ctx), res); err != nil {
+			return nil, httperror.NewGrpcFromCtx(ctx, codes.Internal, "invalid response of %s: %s", fullMethod, err.Error())
+		}
+	}
+	if p.Redact {
+		var roles []string
+		if p.Caller != nil {
+			roles, _, _ = p.Caller(ctx)
+		}
+		res = RedactMessage(res, roles)
+	}
+	return res, nil
+}
+
+// CheckAccess has the signature of CheckAccessFunc generated by
+// protoc-gen-go-allocator, so HTTP handlers enforce the same policy.
+func (p *ServerPolicy) CheckAccess(ctx context.Context, req any, action string) error {
+	return p.Check(ctx, action, req)
+}
+
+func (p *ServerPolicy) authorize(ctx context.Context, fullMethod string, req any) error {
+	if p.Authorizer == nil {
+		return nil
+	}
+	var policy MethodPolicy
+	if p.Policy != nil {
+		policy = p.Policy(fullMethod)
+	}
+	return p.Authorizer(ctx, fullMethod, policy, req)
+}
+
+// UnaryServerInterceptor returns grpc.UnaryServerInterceptor,
+// that checks the request before calling the handler,
+// and the response after.
+func (p *ServerPolicy) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := p.Check(ctx, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		res, err := handler(ctx, req)
+		if err != nil {
+			return res, err
+		}
+		return p.CheckResponse(ctx, info.FullMethod, res)
+	}
+}
+
+// StreamServerInterceptor returns grpc.StreamServerInterceptor,
+// that authorizes the stream before calling the handler,
+// validates each received message, and checks each sent message.
+func (p *ServerPolicy) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := p.authorize(ss.Context(), info.FullMethod, nil); err != nil {
+			return err
+		}
+		if p.Validate || p.ValidateResponses || p.Redact {
+			ss = &policyServerStream{ServerStream: ss, policy: p, fullMethod: info.FullMethod}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// policyServerStream validates each received message,
+// and checks each sent message with CheckResponse
+type policyServerStream struct {
+	grpc.ServerStream
+	policy     *ServerPolicy
+	fullMethod string
+}
+
+func (s *policyServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if !s.policy.Validate {
+		return nil
+	}
+	return ValidateMessage(WithAllViolations(s.Context()), m)
+}
+
+func (s *policyServerStream) SendMsg(m any) error {
+	m, err := s.policy.CheckResponse(s.Context(), s.fullMethod, m)
+	if err != nil {
+		return err
+	}
+	return s.ServerStream.SendMsg(m)
+}
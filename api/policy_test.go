@@ -0,0 +1,240 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/effective-security/protoc-gen-go/api"
+	"github.com/effective-security/protoc-gen-go/e2e"
+	"github.com/effective-security/protoc-gen-go/e2e/httppb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type callerKey struct{}
+
+type caller struct {
+	roles  []string
+	scopes []string
+}
+
+func withCaller(ctx context.Context, roles, scopes []string) context.Context {
+	return context.WithValue(ctx, callerKey{}, &caller{roles: roles, scopes: scopes})
+}
+
+func testPolicy(validate bool) *api.ServerPolicy {
+	return &api.ServerPolicy{
+		Policy: e2e.GetMethodPolicy,
+		Authorizer: api.NewRoleAuthorizer(func(ctx context.Context) ([]string, []string, error) {
+			c, _ := ctx.Value(callerKey{}).(*caller)
+			if c == nil {
+				return nil, nil, status.Error(codes.Unauthenticated, "not authenticated")
+			}
+			return c.roles, c.scopes, nil
+		}),
+		Validate: validate,
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := testPolicy(true).UnaryServerInterceptor()
+	handler := func(ctx context.Context, req any) (any, error) {
+		return req, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: e2e.E2E_GetAnnotation_FullMethodName}
+	validReq := &e2e.AnnotationRequest{ID: "1234567890"}
+
+	ctx := withCaller(context.Background(), []string{"User"}, []string{"API:READ"})
+	res, err := interceptor(ctx, validReq, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, validReq, res)
+
+	_, err = interceptor(ctx, &e2e.AnnotationRequest{}, info, handler)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.EqualError(t, err, "bad_request: ID is required")
+
+	_, err = interceptor(context.Background(), validReq, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = interceptor(withCaller(context.Background(), []string{"Admin"}, []string{"API:READ"}), validReq, info, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = interceptor(withCaller(context.Background(), []string{"User"}, []string{"API:WRITE"}), validReq, info, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// methods without roles are allowed
+	_, err = interceptor(context.Background(), &e2e.Basic{Name: "short"}, &grpc.UnaryServerInfo{FullMethod: e2e.E2E_Hello_FullMethodName}, handler)
+	assert.EqualError(t, err, "bad_request: map: minimum count is 1; name: minimum length is 8; values: minimum count is 1")
+	// gRPC clients receive all violations with errdetails.BadRequest
+	assert.Equal(t, []string{"map", "name", "values"}, badRequestFields(t, err))
+
+	// unknown methods are denied
+	_, err = interceptor(ctx, validReq, &grpc.UnaryServerInfo{FullMethod: "/e2e.E2E/Unknown"}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+// badRequestFields returns the fields of errdetails.BadRequest,
+// as received by gRPC clients
+func badRequestFields(t *testing.T, err error) []string {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	var fields []string
+	for _, d := range status.FromProto(st.Proto()).Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, fv := range br.GetFieldViolations() {
+				fields = append(fields, fv.GetField())
+			}
+		}
+	}
+	return fields
+}
+
+type recvServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	msgs []*e2e.Basic
+}
+
+func (s *recvServerStream) Context() context.Context { return s.ctx }
+
+func (s *recvServerStream) RecvMsg(m any) error {
+	msg := s.msgs[0]
+	s.msgs = s.msgs[1:]
+	proto.Merge(m.(*e2e.Basic), msg)
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := testPolicy(true).StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: e2e.E2E_HelloStream_FullMethodName}
+
+	var received []string
+	handler := func(_ any, ss grpc.ServerStream) error {
+		for {
+			var msg e2e.Basic
+			if err := ss.RecvMsg(&msg); err != nil {
+				return err
+			}
+			received = append(received, msg.Name)
+		}
+	}
+
+	ss := &recvServerStream{
+		ctx: context.Background(),
+		msgs: []*e2e.Basic{
+			{Name: "12345678", Map: map[string]string{"k": "v"}, Values: []string{"1"}},
+			{Name: "invalid"},
+		},
+	}
+	err := interceptor(nil, ss, info, handler)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, []string{"12345678"}, received)
+	assert.Equal(t, []string{"map", "name", "values"}, badRequestFields(t, err))
+
+	err = interceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: "/e2e.E2E/Unknown"}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestServerPolicyCheckAccess(t *testing.T) {
+	handler := httppb.GetE2EHTTPHandler(&streamServer{}, testPolicy(false).CheckAccess)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, e2e.E2E_GetAnnotation_FullMethodName, strings.NewReader(`{"ID":"1234567890"}`))
+	r = r.WithContext(withCaller(r.Context(), []string{"Admin"}, nil))
+	handler(w, r, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestUnaryServerInterceptor_Responses(t *testing.T) {
+	policy := testPolicy(false)
+	policy.Redact = true
+	policy.Caller = func(ctx context.Context) ([]string, []string, error) {
+		c, _ := ctx.Value(callerKey{}).(*caller)
+		if c == nil {
+			return nil, nil, status.Error(codes.Unauthenticated, "not authenticated")
+		}
+		return c.roles, c.scopes, nil
+	}
+	interceptor := policy.UnaryServerInterceptor()
+
+	res := &e2e.CallerStatusResponse{Subject: "denis", Claims: []byte("{}")}
+	handler := func(_ context.Context, _ any) (any, error) {
+		return res, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: e2e.Status_Caller_FullMethodName}
+
+	got, err := interceptor(withCaller(context.Background(), []string{"User"}, nil), &emptypb.Empty{}, info, handler)
+	require.NoError(t, err)
+	assert.Empty(t, got.(*e2e.CallerStatusResponse).Claims)
+
+	got, err = interceptor(withCaller(context.Background(), []string{"Admin"}, nil), &emptypb.Empty{}, info, handler)
+	require.NoError(t, err)
+	assert.Same(t, res, got)
+
+	// the caller is not known
+	got, err = interceptor(context.Background(), &emptypb.Empty{}, info, handler)
+	require.NoError(t, err)
+	assert.Empty(t, got.(*e2e.CallerStatusResponse).Claims)
+
+	policy.ValidateResponses = true
+	info = &grpc.UnaryServerInfo{FullMethod: e2e.E2E_Hello_FullMethodName}
+	_, err = interceptor(context.Background(), &e2e.Basic{Name: "12345678", Map: map[string]string{"k": "v"}, Values: []string{"1"}}, info, func(_ context.Context, _ any) (any, error) {
+		return &e2e.Basic{}, nil
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, err.Error(), "invalid response of /e2e.E2E/Hello: bad_request: map: minimum count is 1")
+
+	// OUTPUT_ONLY fields are allowed in the responses
+	valid := &e2e.Basic{A: "server", Name: "12345678", Map: map[string]string{"k": "v"}, Values: []string{"1"}}
+	got, err = interceptor(context.Background(), valid, info, func(_ context.Context, req any) (any, error) {
+		return req, nil
+	})
+	require.NoError(t, err)
+	assert.Same(t, valid, got)
+}
+
+type sendServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []any
+}
+
+func (s *sendServerStream) Context() context.Context { return s.ctx }
+
+func (s *sendServerStream) SendMsg(m any) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func TestStreamServerInterceptor_Responses(t *testing.T) {
+	policy := testPolicy(false)
+	policy.Redact = true
+	interceptor := policy.StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: e2e.E2E_HelloStream_FullMethodName}
+
+	res := &e2e.CallerStatusResponse{Subject: "denis", Claims: []byte("{}")}
+	ss := &sendServerStream{ctx: context.Background()}
+	err := interceptor(nil, ss, info, func(_ any, ss grpc.ServerStream) error {
+		return ss.SendMsg(res)
+	})
+	require.NoError(t, err)
+	require.Len(t, ss.sent, 1)
+	assert.Empty(t, ss.sent[0].(*e2e.CallerStatusResponse).Claims)
+	assert.NotEmpty(t, res.Claims)
+
+	policy.ValidateResponses = true
+	err = interceptor(nil, ss, info, func(_ any, ss grpc.ServerStream) error {
+		return ss.SendMsg(&e2e.Basic{})
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Len(t, ss.sent, 1)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// RedactMessage redacts msg with its MessageDescription,
+// if it implements HasMessageDescription, see RedactResponse.
+func RedactMessage(msg any, roles []string) any {
+	hmd, ok := msg.(HasMessageDescription)
+	if !ok {
+		return msg
+	}
+	pm, ok := msg.(proto.Message)
+	if !ok {
+		return msg
+	}
+	return RedactResponse(pm, hmd.GetMessageDescription(), roles)
+}
+
+// RedactResponse returns msg with the fields marked with es.api.sensitive
+// option cleared, unless the caller roles have one of es.api.reveal_roles
+// of the field.
+// The nested messages are redacted with FieldMeta.Fields,
+// or with their own MessageDescription, if they implement HasMessageDescription.
+// msg is not modified, the clone is returned if any field is redacted.
+func RedactResponse(msg proto.Message, md *MessageDescription, roles []string) proto.Message {
+	if msg == nil || md == nil {
+		return msg
+	}
+	msgReflect := msg.ProtoReflect()
+	if !msgReflect.IsValid() || !redactFields(msgReflect, md.Fields, roles, false) {
+		return msg
+	}
+
+	res := proto.Clone(msg)
+	redactFields(res.ProtoReflect(), md.Fields, roles, true)
+	return res
+}
+
+// redactFields returns true, if msgReflect has the field to redact.
+// The fields are cleared only if clear is true, otherwise msgReflect
+// is not modified.
+func redactFields(msgReflect protoreflect.Message, fields []*FieldMeta, roles []string, clear bool) bool {
+	pfields := msgReflect.Descriptor().Fields()
+	redacted := false
+	for _, field := range fields {
+		fd := pfields.ByName(protoreflect.Name(field.Name))
+		if fd == nil || !msgReflect.Has(fd) {
+			continue
+		}
+		if field.Sensitive && !containsAny(field.RevealRoles, roles) {
+			if !clear {
+				return true
+			}
+			msgReflect.Clear(fd)
+			redacted = true
+			continue
+		}
+
+		if fd.Message() == nil || (fd.IsMap() && fd.MapValue().Message() == nil) {
+			continue
+		}
+		val := msgReflect.Get(fd)
+		if clear {
+			val = msgReflect.Mutable(fd)
+		}
+
+		switch {
+		case fd.IsList():
+			list := val.List()
+			for i := 0; i < list.Len(); i++ {
+				if redactNested(list.Get(i).Message(), field, roles, clear) {
+					if !clear {
+						return true
+					}
+					redacted = true
+				}
+			}
+		case fd.IsMap():
+			val.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				if redactNested(v.Message(), field, roles, clear) {
+					redacted = true
+				}
+				return clear || !redacted
+			})
+			if redacted && !clear {
+				return true
+			}
+		default:
+			if redactNested(val.Message(), field, roles, clear) {
+				if !clear {
+					return true
+				}
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+func redactNested(msgReflect protoreflect.Message, field *FieldMeta, roles []string, clear bool) bool {
+	fields := field.Fields
+	if len(fields) == 0 {
+		if hmd, ok := msgReflect.Interface().(HasMessageDescription); ok {
+			fields = hmd.GetMessageDescription().GetFields()
+		}
+	}
+	return len(fields) > 0 && redactFields(msgReflect, fields, roles, clear)
+}
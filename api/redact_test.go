@@ -0,0 +1,97 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/effective-security/protoc-gen-go/api"
+	"github.com/effective-security/protoc-gen-go/e2e"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestRedactMessage(t *testing.T) {
+	res := &e2e.CallerStatusResponse{Subject: "denis", Role: "User", Claims: []byte(`{"sub":"denis"}`)}
+
+	redacted := api.RedactMessage(res, []string{"User"})
+	assert.True(t, proto.Equal(&e2e.CallerStatusResponse{Subject: "denis", Role: "User"}, redacted.(proto.Message)))
+	// the response is not modified
+	assert.Equal(t, []byte(`{"sub":"denis"}`), res.Claims)
+
+	redacted = api.RedactMessage(res, nil)
+	assert.Empty(t, redacted.(*e2e.CallerStatusResponse).Claims)
+
+	// revealed to the caller role
+	assert.Same(t, res, api.RedactMessage(res, []string{"User", "Admin"}))
+	// nothing to redact
+	empty := &e2e.CallerStatusResponse{Subject: "denis"}
+	assert.Same(t, empty, api.RedactMessage(empty, nil))
+	// no MessageDescription
+	msg := &emptypb.Empty{}
+	assert.Same(t, msg, api.RedactMessage(msg, nil))
+	assert.Nil(t, api.RedactMessage(nil, nil))
+	assert.Equal(t, (*e2e.CallerStatusResponse)(nil), api.RedactMessage((*e2e.CallerStatusResponse)(nil), nil))
+}
+
+func TestRedactResponse_Nested(t *testing.T) {
+	md := &api.MessageDescription{
+		Name:     "Generic",
+		FullName: "e2e.Generic",
+		Fields: []*api.FieldMeta{
+			{Name: "data", Sensitive: true},
+			{Name: "messages", Fields: []*api.FieldMeta{
+				{Name: "id", Sensitive: true, RevealRoles: []string{"Admin"}},
+			}},
+			{Name: "map2", Fields: []*api.FieldMeta{
+				{Name: "id", Sensitive: true},
+			}},
+			{Name: "nested", Fields: []*api.FieldMeta{
+				{Name: "basic", Sensitive: true},
+			}},
+		},
+	}
+
+	msg := &e2e.Generic{
+		Name: "generic",
+		Data: []byte("secret"),
+		Messages: []*e2e.Generic_Message{
+			{Name: "one", Id: "1"},
+			{Name: "two"},
+		},
+		Map2: map[string]*e2e.Generic_Message{
+			"k": {Name: "k", Id: "2"},
+		},
+		Nested: &e2e.Nested_Message{Basic: &e2e.Basic{Name: "basic"}},
+	}
+	orig := proto.Clone(msg)
+
+	res := api.RedactResponse(msg, md, nil)
+	assert.True(t, proto.Equal(&e2e.Generic{
+		Name: "generic",
+		Messages: []*e2e.Generic_Message{
+			{Name: "one"},
+			{Name: "two"},
+		},
+		Map2: map[string]*e2e.Generic_Message{
+			"k": {Name: "k"},
+		},
+		Nested: &e2e.Nested_Message{},
+	}, res), "%v", res)
+	assert.True(t, proto.Equal(orig, msg))
+
+	// the list is revealed to Admin, the other fields are redacted
+	res = api.RedactResponse(msg, md, []string{"Admin"})
+	assert.Equal(t, "1", res.(*e2e.Generic).Messages[0].Id)
+	assert.Empty(t, res.(*e2e.Generic).Data)
+	assert.Empty(t, res.(*e2e.Generic).Map2["k"].Id)
+
+	// only the nested map has the value to redact
+	msg = &e2e.Generic{Map2: map[string]*e2e.Generic_Message{"k": {Name: "k", Id: "2"}}}
+	res = api.RedactResponse(msg, md, nil)
+	assert.NotSame(t, msg, res)
+	assert.Empty(t, res.(*e2e.Generic).Map2["k"].Id)
+
+	msg = &e2e.Generic{Name: "generic", Messages: []*e2e.Generic_Message{{Name: "one"}}}
+	assert.Same(t, msg, api.RedactResponse(msg, md, nil))
+	assert.Same(t, msg, api.RedactResponse(msg, nil, nil))
+}
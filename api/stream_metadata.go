@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var errHeaderSent = status.Error(codes.Internal, "transport: the stream is done or WriteHeader was already called")
+
+// StreamMetadata captures header and trailer metadata set by the in‑process
+// handler, and returns it to the caller of the proxy client.
+// It implements grpc.ServerTransportStream, so the handler can use
+// grpc.SetHeader, grpc.SendHeader and grpc.SetTrailer on its context.
+type StreamMetadata struct {
+	method     string
+	lock       sync.Mutex
+	header     metadata.MD
+	trailer    metadata.MD
+	headerSent bool
+	headerCh   chan struct{}
+}
+
+// NewStreamMetadata returns StreamMetadata for the full method name.
+func NewStreamMetadata(fullMethod string) *StreamMetadata {
+	return &StreamMetadata{
+		method:   fullMethod,
+		headerCh: make(chan struct{}),
+	}
+}
+
+// NewContext returns the handler context with StreamMetadata attached.
+func (m *StreamMetadata) NewContext(ctx context.Context) context.Context {
+	return grpc.NewContextWithServerTransportStream(ctx, m)
+}
+
+// Method returns the full method name.
+func (m *StreamMetadata) Method() string {
+	return m.method
+}
+
+// SetHeader merges md into the header, it fails if the header was sent.
+func (m *StreamMetadata) SetHeader(md metadata.MD) error {
+	if md.Len() == 0 {
+		return nil
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.headerSent {
+		return errHeaderSent
+	}
+	m.header = metadata.Join(m.header, md)
+	return nil
+}
+
+// SendHeader merges md into the header, and makes the header available to
+// the client. It fails if the header was already sent.
+func (m *StreamMetadata) SendHeader(md metadata.MD) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.headerSent {
+		return errHeaderSent
+	}
+	m.header = metadata.Join(m.header, md)
+	m.sendHeaderLocked()
+	return nil
+}
+
+// SetTrailer merges md into the trailer.
+func (m *StreamMetadata) SetTrailer(md metadata.MD) error {
+	if md.Len() == 0 {
+		return nil
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.trailer = metadata.Join(m.trailer, md)
+	return nil
+}
+
+// Finish must be called when the handler returns,
+// it sends the header if it was not sent yet.
+func (m *StreamMetadata) Finish() {
+	m.flushHeader()
+}
+
+// flushHeader sends the header if it was not sent yet,
+// gRPC sends it before the first message.
+func (m *StreamMetadata) flushHeader() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if !m.headerSent {
+		m.sendHeaderLocked()
+	}
+}
+
+func (m *StreamMetadata) sendHeaderLocked() {
+	m.headerSent = true
+	close(m.headerCh)
+}
+
+// Header blocks until the header is sent by the handler,
+// or the context is done.
+func (m *StreamMetadata) Header(ctx context.Context) (metadata.MD, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-m.headerCh:
+	}
+	return m.sentHeader(), nil
+}
+
+func (m *StreamMetadata) sentHeader() metadata.MD {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.header.Copy()
+}
+
+// Trailer returns the trailer, it must be called after the handler returned.
+func (m *StreamMetadata) Trailer() metadata.MD {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.trailer.Copy()
+}
+
+// ApplyCallOptions copies the header and trailer to the
+// grpc.Header and grpc.Trailer call options of unary RPCs.
+func (m *StreamMetadata) ApplyCallOptions(opts ...grpc.CallOption) {
+	m.Finish()
+	for _, o := range opts {
+		switch opt := o.(type) {
+		case grpc.HeaderCallOption:
+			*opt.HeaderAddr = m.sentHeader()
+		case grpc.TrailerCallOption:
+			*opt.TrailerAddr = m.Trailer()
+		}
+	}
+}
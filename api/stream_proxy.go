@@ -7,19 +7,48 @@ import (
 
 	"github.com/cockroachdb/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // DummyServerStream implements the non‑message pieces of grpc.ServerStream.
+// If MD is set, the header and trailer are captured for the client,
+// otherwise they are ignored.
 type DummyServerStream struct {
 	grpc.ServerStream
 	Ctx context.Context
+	MD  *StreamMetadata
 }
 
-func (d DummyServerStream) Context() context.Context        { return d.Ctx }
-func (d DummyServerStream) SendHeader(md metadata.MD) error { return nil }
-func (d DummyServerStream) SetHeader(md metadata.MD) error  { return nil }
-func (d DummyServerStream) SetTrailer(md metadata.MD)       {}
+func (d DummyServerStream) Context() context.Context { return d.Ctx }
+
+func (d DummyServerStream) SendHeader(md metadata.MD) error {
+	if d.MD == nil {
+		return nil
+	}
+	return d.MD.SendHeader(md)
+}
+
+func (d DummyServerStream) SetHeader(md metadata.MD) error {
+	if d.MD == nil {
+		return nil
+	}
+	return d.MD.SetHeader(md)
+}
+
+func (d DummyServerStream) SetTrailer(md metadata.MD) {
+	if d.MD != nil {
+		_ = d.MD.SetTrailer(md)
+	}
+}
+
+// flushHeader sends the header before the first message.
+func (d DummyServerStream) flushHeader() {
+	if d.MD != nil {
+		d.MD.flushHeader()
+	}
+}
 
 // ServerStreamAdapter lets us capture Send() calls into a channel.
 type ServerStreamAdapter[T any] struct {
@@ -28,6 +57,7 @@ type ServerStreamAdapter[T any] struct {
 }
 
 func (s *ServerStreamAdapter[T]) Send(m *T) error {
+	s.flushHeader()
 	select {
 	case <-s.Context().Done():
 		return s.Context().Err()
@@ -42,6 +72,7 @@ type ProxyClientStream[T any] struct {
 	ErrOnce sync.Once
 	Err     error
 	Ctx     context.Context
+	MD      *StreamMetadata
 }
 
 func (p *ProxyClientStream[T]) Recv() (*T, error) {
@@ -57,8 +88,8 @@ func (p *ProxyClientStream[T]) Recv() (*T, error) {
 }
 
 // implement ClientStream for grpc.ServerStreamingClient:
-func (p *ProxyClientStream[T]) Header() (metadata.MD, error) { return nil, nil }
-func (p *ProxyClientStream[T]) Trailer() metadata.MD         { return nil }
+func (p *ProxyClientStream[T]) Header() (metadata.MD, error) { return clientHeader(p.Ctx, p.MD) }
+func (p *ProxyClientStream[T]) Trailer() metadata.MD         { return clientTrailer(p.MD) }
 func (p *ProxyClientStream[T]) CloseSend() error             { return nil }
 func (p *ProxyClientStream[T]) Context() context.Context     { return p.Ctx }
 
@@ -146,10 +177,274 @@ func (c *ClientStreamAdapter[T]) RecvMsg(m any) error {
 	return nil
 }
 
+// ClientStreamServerAdapter lets the in‑process handler Recv() requests from a
+// channel and captures the SendAndClose() response.
+type ClientStreamServerAdapter[Req any, Res any] struct {
+	DummyServerStream
+	Reqs <-chan *Req
+	Res  *Res
+}
+
+// Recv returns the next request, or EOF when the client closed the stream.
+func (s *ClientStreamServerAdapter[Req, Res]) Recv() (*Req, error) {
+	return recvFromChan(s.Context(), s.Reqs)
+}
+
+// SendAndClose stores the response to be returned by CloseAndRecv.
+func (s *ClientStreamServerAdapter[Req, Res]) SendAndClose(m *Res) error {
+	s.Res = m
+	return nil
+}
+
+// SendMsg is a no-op for client‑streaming RPCs, use SendAndClose.
+func (s *ClientStreamServerAdapter[Req, Res]) SendMsg(m any) error {
+	return nil
+}
+
+// RecvMsg must populate the passed-in message.
+func (s *ClientStreamServerAdapter[Req, Res]) RecvMsg(m any) error {
+	return recvMsgInto(s.Recv, m)
+}
+
+// BidiStreamServerAdapter lets the in‑process handler Recv() requests from a
+// channel and captures Send() calls into another channel.
+type BidiStreamServerAdapter[Req any, Res any] struct {
+	DummyServerStream
+	Reqs <-chan *Req
+	Msgs chan<- *Res
+}
+
+// Recv returns the next request, or EOF when the client closed the stream.
+func (s *BidiStreamServerAdapter[Req, Res]) Recv() (*Req, error) {
+	return recvFromChan(s.Context(), s.Reqs)
+}
+
+// Send passes the response to the client side of the stream.
+func (s *BidiStreamServerAdapter[Req, Res]) Send(m *Res) error {
+	s.flushHeader()
+	select {
+	case <-s.Context().Done():
+		return s.Context().Err()
+	case s.Msgs <- m:
+		return nil
+	}
+}
+
+// SendMsg sends the response, m must be *Res.
+func (s *BidiStreamServerAdapter[Req, Res]) SendMsg(m any) error {
+	msg, ok := m.(*Res)
+	if !ok {
+		return errors.Errorf("unexpected message type %T", m)
+	}
+	return s.Send(msg)
+}
+
+// RecvMsg must populate the passed-in message.
+func (s *BidiStreamServerAdapter[Req, Res]) RecvMsg(m any) error {
+	return recvMsgInto(s.Recv, m)
+}
+
+// ProxyClientStreamingClient adapts the request channel and the result of the
+// in‑process handler into a grpc.ClientStreamingClient.
+// Done must be closed after Res and Err are set, and MD is finished.
+type ProxyClientStreamingClient[Req any, Res any] struct {
+	Reqs   chan<- *Req
+	Done   <-chan struct{}
+	Res    *Res
+	Err    error
+	Ctx    context.Context
+	MD     *StreamMetadata
+	closed bool
+}
+
+// Send passes the request to the handler.
+// It returns io.EOF if the handler has already returned,
+// the actual status is returned by CloseAndRecv.
+func (p *ProxyClientStreamingClient[Req, Res]) Send(m *Req) error {
+	if p.closed {
+		return errSendAfterClose
+	}
+	return sendToChan(p.Ctx, p.Done, p.Reqs, m)
+}
+
+// CloseAndRecv closes the request stream and waits for the handler response.
+func (p *ProxyClientStreamingClient[Req, Res]) CloseAndRecv() (*Res, error) {
+	_ = p.CloseSend()
+	select {
+	case <-p.Ctx.Done():
+		return nil, p.Ctx.Err()
+	case <-p.Done:
+	}
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	if p.Res == nil {
+		return nil, io.EOF
+	}
+	return p.Res, nil
+}
+
+// implement ClientStream for grpc.ClientStreamingClient:
+func (p *ProxyClientStreamingClient[Req, Res]) Header() (metadata.MD, error) {
+	return clientHeader(p.Ctx, p.MD)
+}
+func (p *ProxyClientStreamingClient[Req, Res]) Trailer() metadata.MD     { return clientTrailer(p.MD) }
+func (p *ProxyClientStreamingClient[Req, Res]) Context() context.Context { return p.Ctx }
+
+// CloseSend closes the request channel, the handler receives io.EOF.
+func (p *ProxyClientStreamingClient[Req, Res]) CloseSend() error {
+	if !p.closed {
+		p.closed = true
+		close(p.Reqs)
+	}
+	return nil
+}
+
+// SendMsg sends the request, m must be *Req.
+func (p *ProxyClientStreamingClient[Req, Res]) SendMsg(m any) error {
+	msg, ok := m.(*Req)
+	if !ok {
+		return errors.Errorf("unexpected message type %T", m)
+	}
+	return p.Send(msg)
+}
+
+// RecvMsg must populate the passed-in message with the handler response.
+func (p *ProxyClientStreamingClient[Req, Res]) RecvMsg(m any) error {
+	return recvMsgInto(p.CloseAndRecv, m)
+}
+
+// ProxyBidiStreamingClient adapts the request and response channels of the
+// in‑process handler into a grpc.BidiStreamingClient.
+// Msgs must be closed after Err is set, and MD is finished.
+type ProxyBidiStreamingClient[Req any, Res any] struct {
+	Reqs    chan<- *Req
+	Msgs    <-chan *Res
+	Done    <-chan struct{}
+	ErrOnce sync.Once
+	Err     error
+	Ctx     context.Context
+	MD      *StreamMetadata
+	closed  bool
+}
+
+// Send passes the request to the handler.
+// It returns io.EOF if the handler has already returned,
+// the actual status is returned by Recv.
+func (p *ProxyBidiStreamingClient[Req, Res]) Send(m *Req) error {
+	if p.closed {
+		return errSendAfterClose
+	}
+	return sendToChan(p.Ctx, p.Done, p.Reqs, m)
+}
+
+// Recv gives you the next response, or EOF/error when done.
+func (p *ProxyBidiStreamingClient[Req, Res]) Recv() (*Res, error) {
+	m, ok := <-p.Msgs
+	if ok {
+		return m, nil
+	}
+	// channel closed → return stored error (if any), else EOF
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	return nil, io.EOF
+}
+
+// implement ClientStream for grpc.BidiStreamingClient:
+func (p *ProxyBidiStreamingClient[Req, Res]) Header() (metadata.MD, error) {
+	return clientHeader(p.Ctx, p.MD)
+}
+func (p *ProxyBidiStreamingClient[Req, Res]) Trailer() metadata.MD     { return clientTrailer(p.MD) }
+func (p *ProxyBidiStreamingClient[Req, Res]) Context() context.Context { return p.Ctx }
+
+// CloseSend closes the request channel, the handler receives io.EOF.
+func (p *ProxyBidiStreamingClient[Req, Res]) CloseSend() error {
+	if !p.closed {
+		p.closed = true
+		close(p.Reqs)
+	}
+	return nil
+}
+
+// SendMsg sends the request, m must be *Req.
+func (p *ProxyBidiStreamingClient[Req, Res]) SendMsg(m any) error {
+	msg, ok := m.(*Req)
+	if !ok {
+		return errors.Errorf("unexpected message type %T", m)
+	}
+	return p.Send(msg)
+}
+
+// RecvMsg must populate the passed-in message.
+func (p *ProxyBidiStreamingClient[Req, Res]) RecvMsg(m any) error {
+	return recvMsgInto(p.Recv, m)
+}
+
+var errSendAfterClose = status.Error(codes.Internal, "SendMsg called after CloseSend")
+
+// clientHeader returns the header sent by the handler, if MD is set.
+func clientHeader(ctx context.Context, md *StreamMetadata) (metadata.MD, error) {
+	if md == nil {
+		return nil, nil
+	}
+	return md.Header(ctx)
+}
+
+// clientTrailer returns the trailer set by the handler, if MD is set.
+func clientTrailer(md *StreamMetadata) metadata.MD {
+	if md == nil {
+		return nil
+	}
+	return md.Trailer()
+}
+
+func recvFromChan[T any](ctx context.Context, ch <-chan *T) (*T, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case m, ok := <-ch:
+		if !ok {
+			return nil, io.EOF
+		}
+		return m, nil
+	}
+}
+
+func sendToChan[T any](ctx context.Context, done <-chan struct{}, ch chan<- *T, m *T) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+		// the handler returned, the status is reported by Recv
+		return io.EOF
+	case ch <- m:
+		return nil
+	}
+}
+
+func recvMsgInto[T any](recv func() (*T, error), m any) error {
+	msg, err := recv()
+	if err != nil {
+		return err
+	}
+	out, ok := m.(*T)
+	if !ok {
+		return errors.Errorf("unexpected message type %T", m)
+	}
+	*out = *msg
+	return nil
+}
+
 // Compile‑time check that we satisfy the right gRPC interfaces:
 var (
-	_ grpc.ServerStream               = (*ServerStreamAdapter[int])(nil)
-	_ grpc.ServerStreamingServer[int] = (*ServerStreamAdapter[int])(nil)
-	_ grpc.ClientStream               = (*ClientStreamAdapter[int])(nil)
-	_ grpc.ServerStreamingClient[int] = (*ClientStreamAdapter[int])(nil)
+	_ grpc.ServerStream                       = (*ServerStreamAdapter[int])(nil)
+	_ grpc.ServerStreamingServer[int]         = (*ServerStreamAdapter[int])(nil)
+	_ grpc.ClientStream                       = (*ClientStreamAdapter[int])(nil)
+	_ grpc.ServerStreamingClient[int]         = (*ClientStreamAdapter[int])(nil)
+	_ grpc.ClientStreamingServer[int, string] = (*ClientStreamServerAdapter[int, string])(nil)
+	_ grpc.BidiStreamingServer[int, string]   = (*BidiStreamServerAdapter[int, string])(nil)
+	_ grpc.ClientStreamingClient[int, string] = (*ProxyClientStreamingClient[int, string])(nil)
+	_ grpc.BidiStreamingClient[int, string]   = (*ProxyBidiStreamingClient[int, string])(nil)
+	_ grpc.ServerStreamingClient[int]         = (*ProxyClientStream[int])(nil)
 )
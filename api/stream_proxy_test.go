@@ -0,0 +1,242 @@
+package api_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/effective-security/protoc-gen-go/e2e"
+	"github.com/effective-security/protoc-gen-go/e2e/proxypb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type streamServer struct {
+	e2e.E2EServer
+	err error
+}
+
+func (s *streamServer) Hello(ctx context.Context, req *e2e.Basic) (*e2e.Basic, error) {
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-header", req.Name))
+	_ = grpc.SetTrailer(ctx, metadata.Pairs("x-trailer", req.Name))
+	return &e2e.Basic{Name: "hello " + req.Name}, s.err
+}
+
+func (s *streamServer) HelloStream(req *e2e.Basic, srv grpc.ServerStreamingServer[e2e.Basic]) error {
+	_ = srv.SetHeader(metadata.Pairs("x-header", req.Name))
+	defer srv.SetTrailer(metadata.Pairs("x-trailer", req.Name))
+	for _, v := range req.Values {
+		if err := srv.Send(&e2e.Basic{Name: v}); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+func (s *streamServer) HelloClientStream(srv grpc.ClientStreamingServer[e2e.Basic, e2e.Basic]) error {
+	res := &e2e.Basic{}
+	for {
+		req, err := srv.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		res.Values = append(res.Values, req.Name)
+	}
+	// grpc.SetHeader must work with the stream context
+	_ = grpc.SetHeader(srv.Context(), metadata.Pairs("x-header", "client"))
+	srv.SetTrailer(metadata.Pairs("x-trailer", "client"))
+	if s.err != nil {
+		return s.err
+	}
+	return srv.SendAndClose(res)
+}
+
+func (s *streamServer) HelloBidiStream(srv grpc.BidiStreamingServer[e2e.Basic, e2e.Basic]) error {
+	_ = srv.SendHeader(metadata.Pairs("x-header", "bidi"))
+	for {
+		req, err := srv.Recv()
+		if err == io.EOF {
+			return s.err
+		}
+		if err != nil {
+			return err
+		}
+		if err := srv.Send(&e2e.Basic{Name: "hello " + req.Name}); err != nil {
+			return err
+		}
+	}
+}
+
+func TestProxyUnaryMetadata(t *testing.T) {
+	ctx := context.Background()
+	client := proxypb.E2EServerToClient(&streamServer{})
+
+	var header, trailer metadata.MD
+	res, err := client.Hello(ctx, &e2e.Basic{Name: "1"}, grpc.Header(&header), grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	assert.Equal(t, "hello 1", res.Name)
+	assert.Equal(t, []string{"1"}, header.Get("x-header"))
+	assert.Equal(t, []string{"1"}, trailer.Get("x-trailer"))
+
+	// header and trailer are returned on error as well
+	client = proxypb.E2EServerToClient(&streamServer{err: errors.New("failed")})
+	header, trailer = nil, nil
+	_, err = client.Hello(ctx, &e2e.Basic{Name: "2"}, grpc.Header(&header), grpc.Trailer(&trailer))
+	require.Error(t, err)
+	assert.Equal(t, []string{"2"}, header.Get("x-header"))
+	assert.Equal(t, []string{"2"}, trailer.Get("x-trailer"))
+}
+
+func TestProxyServerStream(t *testing.T) {
+	ctx := context.Background()
+	client := proxypb.E2EServerToClient(&streamServer{})
+
+	stream, err := client.HelloStream(ctx, &e2e.Basic{Name: "req", Values: []string{"1", "2"}})
+	require.NoError(t, err)
+
+	var names []string
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, msg.Name)
+	}
+	assert.Equal(t, []string{"1", "2"}, names)
+
+	header, err := stream.Header()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req"}, header.Get("x-header"))
+	assert.Equal(t, []string{"req"}, stream.Trailer().Get("x-trailer"))
+
+	client = proxypb.E2EServerToClient(&streamServer{err: errors.New("failed")})
+	stream, err = client.HelloStream(ctx, &e2e.Basic{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.EqualError(t, err, "failed")
+}
+
+func TestProxyClientStream(t *testing.T) {
+	ctx := context.Background()
+	client := proxypb.E2EServerToClient(&streamServer{})
+
+	stream, err := client.HelloClientStream(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&e2e.Basic{Name: "1"}))
+	require.NoError(t, stream.Send(&e2e.Basic{Name: "2"}))
+	res, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, res.Values)
+
+	header, err := stream.Header()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"client"}, header.Get("x-header"))
+	assert.Equal(t, []string{"client"}, stream.Trailer().Get("x-trailer"))
+
+	client = proxypb.E2EServerToClient(&streamServer{err: errors.New("failed")})
+	stream, err = client.HelloClientStream(ctx)
+	require.NoError(t, err)
+	_, err = stream.CloseAndRecv()
+	assert.EqualError(t, err, "failed")
+}
+
+func TestProxyBidiStream(t *testing.T) {
+	ctx := context.Background()
+	client := proxypb.E2EServerToClient(&streamServer{})
+
+	stream, err := client.HelloBidiStream(ctx)
+	require.NoError(t, err)
+
+	// header is available before the first message
+	header, err := stream.Header()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bidi"}, header.Get("x-header"))
+
+	for _, name := range []string{"1", "2"} {
+		require.NoError(t, stream.Send(&e2e.Basic{Name: name}))
+		msg, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, "hello "+name, msg.Name)
+	}
+	require.NoError(t, stream.CloseSend())
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	client = proxypb.E2EServerToClient(&streamServer{err: errors.New("failed")})
+	stream, err = client.HelloBidiStream(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.CloseSend())
+	_, err = stream.Recv()
+	assert.EqualError(t, err, "failed")
+
+	assert.EqualError(t, stream.Send(&e2e.Basic{}), "rpc error: code = Internal desc = SendMsg called after CloseSend")
+}
+
+// bidiServerStream is the caller side of the Server to Client bidi proxy
+type bidiServerStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	reqs    chan *e2e.Basic
+	sendErr error
+}
+
+func (s *bidiServerStream) Context() context.Context { return s.ctx }
+
+func (s *bidiServerStream) Recv() (*e2e.Basic, error) {
+	if s.reqs == nil {
+		return &e2e.Basic{Name: "req"}, nil
+	}
+	req, ok := <-s.reqs
+	if !ok {
+		return nil, io.EOF
+	}
+	return req, nil
+}
+
+func (s *bidiServerStream) Send(*e2e.Basic) error { return s.sendErr }
+
+// stuckServer sends one response and stops reading requests
+// until the stream is cancelled
+type stuckServer struct {
+	streamServer
+	stopped chan struct{}
+}
+
+func (s *stuckServer) HelloBidiStream(srv grpc.BidiStreamingServer[e2e.Basic, e2e.Basic]) error {
+	defer close(s.stopped)
+	if err := srv.Send(&e2e.Basic{Name: "hello"}); err != nil {
+		return err
+	}
+	<-srv.Context().Done()
+	return srv.Context().Err()
+}
+
+func TestProxyBidiStreamServerToClient(t *testing.T) {
+	ctx := context.Background()
+	client := proxypb.NewE2EClientFromProxy(proxypb.E2EServerToClient(&streamServer{err: errors.New("failed")}))
+
+	reqs := make(chan *e2e.Basic)
+	close(reqs)
+	err := client.HelloBidiStream(&bidiServerStream{ctx: ctx, reqs: reqs})
+	assert.EqualError(t, err, "failed")
+
+	// the remote stream is cancelled when the caller is gone
+	upstream := &stuckServer{stopped: make(chan struct{})}
+	client = proxypb.NewE2EClientFromProxy(proxypb.E2EServerToClient(upstream))
+	err = client.HelloBidiStream(&bidiServerStream{ctx: ctx, sendErr: errors.New("caller gone")})
+	assert.EqualError(t, err, "caller gone")
+
+	select {
+	case <-upstream.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("remote stream is not cancelled")
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"runtime/debug"
+	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/effective-security/porto/xhttp/httperror"
 	"github.com/effective-security/xlog"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
@@ -19,8 +22,29 @@ type Validator interface {
 	Validate(ctx context.Context) error
 }
 
+// ValidateMessage validates req, if it implements Validator.
+// The error with gRPC status, like Internal error of the failed validation,
+// is returned as is, other errors are returned with InvalidArgument code.
+func ValidateMessage(ctx context.Context, req any) error {
+	v, ok := req.(Validator)
+	if !ok {
+		return nil
+	}
+	err := v.Validate(ctx)
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s", err.Error())
+}
+
 // ValidateRequest validates a protobuf request message using its MessageDescription metadata.
-// It checks for required fields, string length constraints, and numeric value constraints.
+// It checks for required fields, string length and format constraints, and numeric value constraints.
+// By default the first violation is returned, use WithAllViolations to collect
+// all violations into ValidationError.
+// A panic during validation is handled according to SetValidationPanicPolicy.
 // The fields in the MessageDescription are annotated with the validation constraints:
 /*
 	// Required is the option for the field to be required.
@@ -38,9 +62,47 @@ type Validator interface {
 	MinCount int32
 	// MaxCount is the option for the field maximum count for lists.
 	MaxCount int32
+	// Pattern is the option for the field to match the regular expression.
+	Pattern string
+	// Prefix is the option for the field to start with the value.
+	Prefix string
+	// Suffix is the option for the field to end with the value.
+	Suffix string
+	// Format is the option for the field to be in a well-known format:
+	// email|uri|hostname|ip|ipv4|ipv6|uuid|ulid.
+	Format string
+	// In is the option for the field value to be one of the listed values.
+	In []string
+	// NotIn is the option for the field value to not be one of the listed values.
+	NotIn []string
+	// Range is the option for the field value range of numbers,
+	// with 64-bit and fractional, inclusive or exclusive bounds.
+	Range *NumberRange
+	// TimeRange is the option for the Timestamp and Duration field bounds.
+	TimeRange *TimeRange
+	// Unique is the option for the items of repeated fields to be unique.
+	Unique bool
+	// Items is the option for the item constraints of repeated fields,
+	// the value constraints of the field are not applied to the items.
+	Items *ItemRules
+	// Keys is the option for the key constraints of map fields.
+	Keys *ItemRules
+	// OutputOnly is populated from google.api.field_behavior OUTPUT_ONLY,
+	// and REQUIRED behavior is populated to Required.
+	OutputOnly bool
 */
-
-func ValidateRequest(ctx context.Context, req proto.Message, md *MessageDescription) (err error) {
+// Enum values must be defined by EnumDescription, and bitmask enums
+// must have only the defined flags set.
+// The fields with explicit presence, such as proto3 optional, are validated
+// only if set, the unset optional number is not treated as 0.
+// OUTPUT_ONLY fields must not be set in the requests, and are not validated,
+// unless the context is WithResponseValidation.
+// Only the set member of a oneof group is validated, and a required group
+// of MessageDescription.Oneofs must have one of the members set.
+// MessageDescription.Rules are evaluated after the field constraints,
+// see MustCompileMessageRules.
+
+func ValidateRequest(ctx context.Context, req proto.Message, md *MessageDescription) error {
 	if req == nil {
 		return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: request cannot be nil", md.Name)
 	}
@@ -55,19 +117,33 @@ func ValidateRequest(ctx context.Context, req proto.Message, md *MessageDescript
 		return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: is not a valid protobuf message", md.GetDisplayName())
 	}
 
+	return runValidation(ctx, msgReflect, md, func(v *validation) error {
+		err := v.validateReflectFields(msgReflect, md.Fields, "")
+		if err == nil && len(md.Oneofs) > 0 {
+			err = v.validateOneofs(msgReflect, md.Oneofs)
+		}
+		return err
+	})
+}
+
+// runValidation runs the field checks of fn and the message-level rules,
+// it handles the panic and reports the result.
+func runValidation(ctx context.Context, msgReflect protoreflect.Message, md *MessageDescription, fn func(v *validation) error) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			logger.ContextKV(ctx, xlog.ERROR,
-				"reason", "panic",
-				"struct", md.Name,
-				"err", r,
-				"stack", string(debug.Stack()))
-			// in case of panic, we set err to nil to avoid returning the panic error
-			err = nil
+			err = recoverValidation(ctx, md, r)
 		}
 	}()
 
-	err = validateReflectFields(ctx, msgReflect, md.Fields, "")
+	v := &validation{ctx: ctx, all: allViolations(ctx), response: isResponseValidation(ctx)}
+	err = fn(v)
+	if err == nil && len(md.Rules) > 0 {
+		// message-level rules are evaluated after the field constraints
+		err = v.validateRules(msgReflect, md)
+	}
+	if err == nil && len(v.violations) > 0 {
+		err = &ValidationError{Violations: v.violations}
+	}
 	if err != nil {
 		logger.ContextKV(ctx, xlog.WARNING,
 			"reason", "validateReflectFields",
@@ -78,21 +154,164 @@ func ValidateRequest(ctx context.Context, req proto.Message, md *MessageDescript
 	return
 }
 
-func validateReflectFields(ctx context.Context, msgReflect protoreflect.Message, fields []*FieldMeta, prefix string) error {
+// PanicPolicy specifies how ValidateRequest handles a panic,
+// for example caused by malformed MessageDescription.
+type PanicPolicy int32
+
+const (
+	// PanicFailOpen treats the request as valid, this is the default
+	PanicFailOpen PanicPolicy = iota
+	// PanicFailClosed rejects the request with Internal error
+	PanicFailClosed
+	// PanicRepanic propagates the panic to the caller
+	PanicRepanic
+)
+
+// PanicHook is called when ValidateRequest recovers from a panic,
+// it can be used to emit metrics or alerts.
+type PanicHook func(ctx context.Context, messageName string, recovered any)
+
+var (
+	validationPanicPolicy atomic.Int32
+	validationPanicHook   atomic.Pointer[PanicHook]
+)
+
+// SetValidationPanicPolicy sets PanicPolicy for ValidateRequest.
+// Security-sensitive services should use PanicFailClosed.
+func SetValidationPanicPolicy(policy PanicPolicy) {
+	validationPanicPolicy.Store(int32(policy))
+}
+
+// SetValidationPanicHook sets the hook called on panic in ValidateRequest,
+// nil removes the hook.
+func SetValidationPanicHook(hook PanicHook) {
+	if hook == nil {
+		validationPanicHook.Store(nil)
+		return
+	}
+	validationPanicHook.Store(&hook)
+}
+
+// recoverValidation handles the recovered panic according to PanicPolicy
+func recoverValidation(ctx context.Context, md *MessageDescription, r any) error {
+	policy := PanicPolicy(validationPanicPolicy.Load())
+	logger.ContextKV(ctx, xlog.ERROR,
+		"reason", "panic",
+		"struct", md.Name,
+		"policy", policy,
+		"err", r,
+		"stack", string(debug.Stack()))
+
+	if hook := validationPanicHook.Load(); hook != nil {
+		(*hook)(ctx, md.Name, r)
+	}
+
+	switch policy {
+	case PanicFailClosed:
+		return httperror.NewGrpcFromCtx(ctx, codes.Internal, "%s: failed to validate request", md.Name)
+	case PanicRepanic:
+		panic(r)
+	}
+	// fail open to avoid rejecting requests, as before
+	return nil
+}
+
+// validation holds the state of ValidateRequest
+type validation struct {
+	ctx context.Context
+	// all specifies to collect all violations,
+	// otherwise the first violation is returned as error
+	all bool
+	// response specifies that the response is validated,
+	// where the OUTPUT_ONLY fields are allowed
+	response   bool
+	violations []*FieldViolation
+}
+
+// violation describes the failed rule
+type violation struct {
+	// Field is the field of the rule, nil for the message-level rules
+	Field *FieldMeta
+	Path  string
+	Rule  string
+	// Code is the error code, by default the code of the Rule
+	Code string
+	// Display is the display name, by default the Field display name
+	Display string
+	Limit   any
+	Actual  any
+	// Params are the additional parameters of the message
+	Params map[string]string
+}
+
+// violate records the violation, and returns error if the validation must stop,
+// the description is rendered by MessageCatalog of the context,
+// or by format and args in English.
+func (v *validation) violate(vi violation, format string, args ...any) error {
+	fv := &FieldViolation{
+		Field:   vi.Path,
+		Rule:    vi.Rule,
+		Code:    vi.Code,
+		Display: vi.Display,
+		Params:  vi.Params,
+	}
+	if fv.Code == "" {
+		fv.Code = ruleCodes[vi.Rule]
+	}
+	if fv.Display == "" && vi.Field != nil {
+		fv.Display = vi.Field.GetDisplayName()
+	}
+	if vi.Limit != nil {
+		fv.Limit = fmt.Sprint(vi.Limit)
+	}
+	if vi.Actual != nil {
+		fv.Actual = fmt.Sprint(vi.Actual)
+	}
+	if catalog := messageCatalog(v.ctx); catalog != nil {
+		fv.Description = catalog.Message(v.ctx, fv)
+	}
+	if fv.Description == "" {
+		fv.Description = fmt.Sprintf(format, args...)
+	}
+
+	if !v.all {
+		// the first violation carries the same data, as when all are collected
+		return &ValidationError{Violations: []*FieldViolation{fv}}
+	}
+	v.violations = append(v.violations, fv)
+	return nil
+}
+
+func (v *validation) validateReflectFields(msgReflect protoreflect.Message, fields []*FieldMeta, prefix string) error {
 	pfields := msgReflect.Descriptor().Fields()
 	for _, field := range fields {
 		fd := pfields.ByName(protoreflect.Name(field.Name))
 		if fd == nil {
 			continue
 		}
+		if od := fd.ContainingOneof(); od != nil && !od.IsSynthetic() {
+			// only the set member of oneof is validated
+			if set := msgReflect.WhichOneof(od); set != nil && set != fd {
+				continue
+			}
+		}
 
-		v := msgReflect.Get(fd)
+		val := msgReflect.Get(fd)
 		fieldPath := field.Name
 		if prefix != "" {
 			fieldPath = prefix + "." + field.Name
 		}
 
-		valuePresent := hasFieldValue(msgReflect, fd, v)
+		if field.OutputOnly && !v.response {
+			if msgReflect.Has(fd) {
+				if err := v.violateOutputOnly(field, fieldPath); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		valuePresent := hasFieldValue(msgReflect, fd, val)
 
 		// Check RequiredOr fields
 		if len(field.RequiredOr) > 0 {
@@ -113,32 +332,43 @@ func validateReflectFields(ctx context.Context, msgReflect protoreflect.Message,
 				}
 			}
 			if !isValid {
-				return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: at least one of the fields must be set: %s", fieldPath, strings.Join(field.RequiredOr, ", "))
+				if err := v.violateRequiredOr(field, fieldPath); err != nil {
+					return err
+				}
+				continue
 			}
 		}
 
 		// Check required fields
 		if field.Required {
 			if !valuePresent {
-				return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s is required", fieldPath)
+				if err := v.violate(violation{Field: field, Path: fieldPath, Rule: RuleRequired}, "%s is required", fieldPath); err != nil {
+					return err
+				}
+				continue
 			}
 		}
 
+		if !valuePresent && fd.HasPresence() {
+			// the value of explicit presence field is checked only if set
+			continue
+		}
+
 		if fd.IsList() {
-			if err := validateListField(ctx, v, fd, field, fieldPath); err != nil {
+			if err := v.validateListField(val, fd, field, fieldPath); err != nil {
 				return err
 			}
 			continue
 		}
 
 		if fd.IsMap() {
-			if err := validateMapField(ctx, v, fd, field, fieldPath); err != nil {
+			if err := v.validateMapField(val, fd, field, fieldPath); err != nil {
 				return err
 			}
 			continue
 		}
 
-		if err := validateSingularValue(ctx, v, fd.Kind(), field, fieldPath); err != nil {
+		if err := v.validateSingularValue(val, fd.Kind(), field, fieldPath); err != nil {
 			return err
 		}
 	}
@@ -146,21 +376,80 @@ func validateReflectFields(ctx context.Context, msgReflect protoreflect.Message,
 	return nil
 }
 
-func validateListField(ctx context.Context, listValue protoreflect.Value, fd protoreflect.FieldDescriptor, field *FieldMeta, fieldPath string) error {
-	plist := listValue.List()
-	length := plist.Len()
+func (v *validation) violateOutputOnly(field *FieldMeta, fieldPath string) error {
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleOutputOnly}, "%s: output only field cannot be set", fieldPath)
+}
 
+// validateOneofs checks that the required oneof groups have a member set
+func (v *validation) validateOneofs(msgReflect protoreflect.Message, oneofs []*OneofMeta) error {
+	ods := msgReflect.Descriptor().Oneofs()
+	for _, oneof := range oneofs {
+		if !oneof.Required {
+			continue
+		}
+		od := ods.ByName(protoreflect.Name(oneof.Name))
+		if od == nil || msgReflect.WhichOneof(od) != nil {
+			continue
+		}
+		if err := v.violateOneof(oneof); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *validation) violateRequiredOr(field *FieldMeta, fieldPath string) error {
+	fields := strings.Join(field.RequiredOr, ", ")
+	vi := violation{
+		Field:  field,
+		Path:   fieldPath,
+		Rule:   RuleRequiredOr,
+		Limit:  fields,
+		Params: map[string]string{ParamFields: fields},
+	}
+	return v.violate(vi, "%s: at least one of the fields must be set: %s", fieldPath, fields)
+}
+
+func (v *validation) violateOneof(oneof *OneofMeta) error {
+	fields := strings.Join(oneof.Fields, ", ")
+	vi := violation{
+		Path:    oneof.Name,
+		Display: oneof.Name,
+		Rule:    RuleOneof,
+		Limit:   fields,
+		Params:  map[string]string{ParamFields: fields},
+	}
+	return v.violate(vi, "%s: one of the fields must be set: %s", oneof.Name, fields)
+}
+
+func (v *validation) validateCount(length int, field *FieldMeta, fieldPath string) error {
 	if field.MinCount > 0 && length < int(field.MinCount) {
-		return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: minimum count is %d", fieldPath, field.MinCount)
+		return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleMinCount, Limit: field.MinCount, Actual: length}, "%s: minimum count is %d", fieldPath, field.MinCount)
 	}
 	if field.MaxCount > 0 && length > int(field.MaxCount) {
-		return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: maximum count is %d", fieldPath, field.MaxCount)
+		return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleMaxCount, Limit: field.MaxCount, Actual: length}, "%s: maximum count is %d", fieldPath, field.MaxCount)
+	}
+	return nil
+}
+
+func (v *validation) validateListField(listValue protoreflect.Value, fd protoreflect.FieldDescriptor, field *FieldMeta, fieldPath string) error {
+	plist := listValue.List()
+	length := plist.Len()
+
+	if err := v.validateCount(length, field, fieldPath); err != nil {
+		return err
+	}
+
+	if field.Unique {
+		if err := v.validateUnique(plist, fd.Kind(), field, fieldPath); err != nil {
+			return err
+		}
 	}
 
 	if fd.Kind() == protoreflect.MessageKind && len(field.Fields) > 0 {
 		for i := 0; i < length; i++ {
 			elementPath := fmt.Sprintf("%s[%d]", fieldPath, i)
-			if err := validateReflectFields(ctx, plist.Get(i).Message(), field.Fields, elementPath); err != nil {
+			if err := v.validateReflectFields(plist.Get(i).Message(), field.Fields, elementPath); err != nil {
 				return err
 			}
 		}
@@ -169,32 +458,48 @@ func validateListField(ctx context.Context, listValue protoreflect.Value, fd pro
 
 	for i := 0; i < length; i++ {
 		elementPath := fmt.Sprintf("%s[%d]", fieldPath, i)
-		if err := validateSingularValue(ctx, plist.Get(i), fd.Kind(), field, elementPath); err != nil {
+		if err := v.validateElement(plist.Get(i), fd.Kind(), field, elementPath); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func validateMapField(ctx context.Context, mapValue protoreflect.Value, fd protoreflect.FieldDescriptor, field *FieldMeta, fieldPath string) error {
+// validateElement checks the item of repeated field with es.api.items rules,
+// the other rules of the field are applied to the list itself.
+func (v *validation) validateElement(val protoreflect.Value, kind protoreflect.Kind, field *FieldMeta, fieldPath string) error {
+	switch kind {
+	case protoreflect.EnumKind:
+		// the item must be the value of the enum
+		return v.validateEnum(int32(val.Enum()), field.ItemMeta(), fieldPath)
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if msgVal := val.Message(); len(field.Fields) > 0 && msgVal.IsValid() {
+			return v.validateReflectFields(msgVal, field.Fields, fieldPath)
+		}
+		return nil
+	}
+	return v.validateItem(val, kind, field.ItemMeta(), fieldPath)
+}
+
+func (v *validation) validateMapField(mapValue protoreflect.Value, fd protoreflect.FieldDescriptor, field *FieldMeta, fieldPath string) error {
 	pmap := mapValue.Map()
-	length := pmap.Len()
 
-	if field.MinCount > 0 && length < int(field.MinCount) {
-		return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: minimum count is %d", fieldPath, field.MinCount)
-	}
-	if field.MaxCount > 0 && length > int(field.MaxCount) {
-		return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: maximum count is %d", fieldPath, field.MaxCount)
+	if err := v.validateCount(pmap.Len(), field, fieldPath); err != nil {
+		return err
 	}
 
+	mk := fd.MapKey()
 	mv := fd.MapValue()
 	var err error
 	pmap.Range(func(key protoreflect.MapKey, val protoreflect.Value) bool {
+		if err = v.validateItem(key.Value(), mk.Kind(), field.KeyMeta(), fmt.Sprintf("%s.keys[%s]", fieldPath, key.String())); err != nil {
+			return false
+		}
 		elementPath := fmt.Sprintf("%s[%s]", fieldPath, key.String())
 		if mv.Kind() == protoreflect.MessageKind && len(field.Fields) > 0 {
-			err = validateReflectFields(ctx, val.Message(), field.Fields, elementPath)
+			err = v.validateReflectFields(val.Message(), field.Fields, elementPath)
 		} else {
-			err = validateSingularValue(ctx, val, mv.Kind(), field, elementPath)
+			err = v.validateSingularValue(val, mv.Kind(), field, elementPath)
 		}
 		return err == nil
 	})
@@ -204,35 +509,56 @@ func validateMapField(ctx context.Context, mapValue protoreflect.Value, fd proto
 	return nil
 }
 
-func validateSingularValue(ctx context.Context, fieldValue protoreflect.Value, kind protoreflect.Kind, field *FieldMeta, fieldPath string) error {
+func (v *validation) validateSingularValue(fieldValue protoreflect.Value, kind protoreflect.Kind, field *FieldMeta, fieldPath string) error {
 	if kind == protoreflect.StringKind {
 		strVal := fieldValue.String()
-		if field.Min > 0 && len(strVal) < int(field.Min) {
-			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: minimum length is %d", fieldPath, field.Min)
+		if err := v.validateLength(len(strVal), field, fieldPath); err != nil {
+			return err
 		}
-		if field.Max > 0 && len(strVal) > int(field.Max) {
-			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: maximum length is %d", fieldPath, field.Max)
+		if err := v.validateString(strVal, field, fieldPath); err != nil {
+			return err
+		}
+		if err := v.validateIn(strVal, field, fieldPath); err != nil {
+			return err
 		}
 	}
 
-	if kind == protoreflect.BytesKind {
-		bytesVal := fieldValue.Bytes()
-		if field.Min > 0 && len(bytesVal) < int(field.Min) {
-			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: minimum length is %d", fieldPath, field.Min)
+	if kind == protoreflect.EnumKind {
+		if err := v.validateEnum(int32(fieldValue.Enum()), field, fieldPath); err != nil {
+			return err
 		}
-		if field.Max > 0 && len(bytesVal) > int(field.Max) {
-			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: maximum length is %d", fieldPath, field.Max)
+	}
+
+	if kind == protoreflect.BytesKind {
+		if err := v.validateLength(len(fieldValue.Bytes()), field, fieldPath); err != nil {
+			return err
 		}
 	}
 
-	if err := checkNumericConstraints(ctx, fieldValue, kind, field, fieldPath); err != nil {
+	if err := v.checkNumericConstraints(fieldValue, kind, field, fieldPath); err != nil {
+		return err
+	}
+	if err := v.validateRange(fieldValue, kind, field, fieldPath); err != nil {
+		return err
+	}
+
+	if err := v.validateIntIn(fieldValue, kind, field, fieldPath); err != nil {
 		return err
 	}
 
+	if kind == protoreflect.MessageKind && field.TimeRange != nil {
+		msgVal := fieldValue.Message()
+		if msgVal.IsValid() {
+			if err := v.validateTimeRange(msgVal, field, fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+
 	if kind == protoreflect.MessageKind && len(field.Fields) > 0 {
 		msgVal := fieldValue.Message()
 		if msgVal.IsValid() {
-			if err := validateReflectFields(ctx, msgVal, field.Fields, fieldPath); err != nil {
+			if err := v.validateReflectFields(msgVal, field.Fields, fieldPath); err != nil {
 				return err
 			}
 		}
@@ -241,10 +567,56 @@ func validateSingularValue(ctx context.Context, fieldValue protoreflect.Value, k
 	return nil
 }
 
+func (v *validation) violateMin(field *FieldMeta, fieldPath string, actual any) error {
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleMin, Limit: field.Min, Actual: actual}, "%s: minimum value is %d", fieldPath, field.Min)
+}
+
+func (v *validation) violateMax(field *FieldMeta, fieldPath string, actual any) error {
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleMax, Limit: field.Max, Actual: actual}, "%s: maximum value is %d", fieldPath, field.Max)
+}
+
+// validateIntIn checks in and not_in options for integers
+func (v *validation) validateIntIn(fieldValue protoreflect.Value, kind protoreflect.Kind, field *FieldMeta, fieldPath string) error {
+	if len(field.In) == 0 && len(field.NotIn) == 0 {
+		return nil
+	}
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return v.validateIn(strconv.FormatInt(fieldValue.Int(), 10), field, fieldPath)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return v.validateIn(strconv.FormatUint(fieldValue.Uint(), 10), field, fieldPath)
+	}
+	return nil
+}
+
+func (v *validation) validateLength(length int, field *FieldMeta, fieldPath string) error {
+	if field.Min > 0 && length < int(field.Min) {
+		return v.violateMinLength(field, fieldPath, length)
+	}
+	if field.Max > 0 && length > int(field.Max) {
+		return v.violateMaxLength(field, fieldPath, length)
+	}
+	return nil
+}
+
+func (v *validation) violateMinLength(field *FieldMeta, fieldPath string, length int) error {
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleMinLength, Limit: field.Min, Actual: length}, "%s: minimum length is %d", fieldPath, field.Min)
+}
+
+func (v *validation) violateMaxLength(field *FieldMeta, fieldPath string, length int) error {
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleMaxLength, Limit: field.Max, Actual: length}, "%s: maximum length is %d", fieldPath, field.Max)
+}
+
 func hasFieldValue(msg protoreflect.Message, fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
 	if msg.Has(fd) {
 		return true
 	}
+	if fd.HasPresence() {
+		// proto3 optional, oneof members and editions fields with explicit
+		// presence are not set, even if the default is not empty
+		return false
+	}
 	if !value.IsValid() {
 		return false
 	}
@@ -279,31 +651,31 @@ func isEmpty(value protoreflect.Value, fd protoreflect.FieldDescriptor) bool {
 	}
 }
 
-func checkNumericConstraints(ctx context.Context, fieldValue protoreflect.Value, kind protoreflect.Kind, field *FieldMeta, fieldPath string) error {
+func (v *validation) checkNumericConstraints(fieldValue protoreflect.Value, kind protoreflect.Kind, field *FieldMeta, fieldPath string) error {
 	switch kind {
 	case protoreflect.Int32Kind, protoreflect.Int64Kind:
 		val := fieldValue.Int()
 		if field.Min != 0 && val < int64(field.Min) {
-			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: minimum value is %d", fieldPath, field.Min)
+			return v.violateMin(field, fieldPath, val)
 		}
 		if field.Max != 0 && val > int64(field.Max) {
-			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: maximum value is %d", fieldPath, field.Max)
+			return v.violateMax(field, fieldPath, val)
 		}
 	case protoreflect.Uint32Kind, protoreflect.Uint64Kind, protoreflect.Fixed32Kind, protoreflect.Fixed64Kind:
 		val := fieldValue.Uint()
 		if field.Min > 0 && val < uint64(field.Min) {
-			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: minimum value is %d", fieldPath, field.Min)
+			return v.violateMin(field, fieldPath, val)
 		}
 		if field.Max > 0 && val > uint64(field.Max) {
-			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: maximum value is %d", fieldPath, field.Max)
+			return v.violateMax(field, fieldPath, val)
 		}
 	case protoreflect.FloatKind, protoreflect.DoubleKind:
 		val := fieldValue.Float()
 		if field.Min > 0 && val < float64(field.Min) {
-			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: minimum value is %d", fieldPath, field.Min)
+			return v.violateMin(field, fieldPath, val)
 		}
 		if field.Max > 0 && val > float64(field.Max) {
-			return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: maximum value is %d", fieldPath, field.Max)
+			return v.violateMax(field, fieldPath, val)
 		}
 	}
 	return nil
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// validateEnum checks that the enum value is defined by EnumDescription,
+// and for bitmask enums, that only defined flags are set.
+func (v *validation) validateEnum(num int32, field *FieldMeta, fieldPath string) error {
+	ed := field.EnumDescription
+	if ed != nil {
+		if ed.IsBitmask {
+			if invalid := num &^ ed.flags(); invalid != 0 {
+				// the value is reported, do not check in/not_in
+				return v.violateEnumFlags(field, fieldPath, num, invalid)
+			}
+		} else if !ed.isDefined(num) {
+			return v.violateEnum(field, fieldPath, num)
+		}
+	}
+
+	return v.validateEnumIn(num, field, fieldPath)
+}
+
+// validateEnumIn checks in and not_in options for enums
+func (v *validation) validateEnumIn(num int32, field *FieldMeta, fieldPath string) error {
+	ed := field.EnumDescription
+	isBitmask := ed != nil && ed.IsBitmask
+	if len(field.In) > 0 {
+		in := ed.values(field.In)
+		var allowed bool
+		if isBitmask {
+			allowed = num&^union(in) == 0
+		} else {
+			allowed = slices.Contains(in, num)
+		}
+		if !allowed {
+			if err := v.violateIn(field, fieldPath, num); err != nil {
+				return err
+			}
+		}
+	}
+	if len(field.NotIn) > 0 {
+		notIn := ed.values(field.NotIn)
+		var denied bool
+		if isBitmask {
+			denied = num&union(notIn) != 0
+		} else {
+			denied = slices.Contains(notIn, num)
+		}
+		if denied {
+			return v.violateNotIn(field, fieldPath, num)
+		}
+	}
+	return nil
+}
+
+// validateIn checks in and not_in options for strings and integers
+func (v *validation) validateIn(val string, field *FieldMeta, fieldPath string) error {
+	if len(field.In) > 0 && !slices.Contains(field.In, val) {
+		if err := v.violateIn(field, fieldPath, val); err != nil {
+			return err
+		}
+	}
+	if len(field.NotIn) > 0 && slices.Contains(field.NotIn, val) {
+		return v.violateNotIn(field, fieldPath, val)
+	}
+	return nil
+}
+
+func (v *validation) violateEnum(field *FieldMeta, fieldPath string, num int32) error {
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleEnum, Actual: num}, "%s: invalid enum value: %d", fieldPath, num)
+}
+
+func (v *validation) violateEnumFlags(field *FieldMeta, fieldPath string, num, invalid int32) error {
+	vi := violation{
+		Field:  field,
+		Path:   fieldPath,
+		Rule:   RuleEnum,
+		Code:   CodeEnumFlags,
+		Actual: num,
+		Params: map[string]string{ParamFlags: strconv.FormatInt(int64(invalid), 10)},
+	}
+	return v.violate(vi, "%s: invalid enum flags: %d", fieldPath, invalid)
+}
+
+func (v *validation) violateIn(field *FieldMeta, fieldPath string, actual any) error {
+	list := strings.Join(field.In, ",")
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleIn, Limit: list, Actual: actual}, "%s: must be one of: %s", fieldPath, list)
+}
+
+func (v *validation) violateNotIn(field *FieldMeta, fieldPath string, actual any) error {
+	list := strings.Join(field.NotIn, ",")
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleNotIn, Limit: list, Actual: actual}, "%s: must not be one of: %s", fieldPath, list)
+}
+
+// flags returns the union of all defined values of bitmask enum
+func (e *EnumDescription) flags() int32 {
+	var res int32
+	for _, enum := range e.Enums {
+		res |= enum.Value
+	}
+	return res
+}
+
+func (e *EnumDescription) isDefined(num int32) bool {
+	for _, enum := range e.Enums {
+		if enum.Value == num {
+			return true
+		}
+	}
+	return false
+}
+
+// values returns the enum numbers of the tokens,
+// specified by number or by name, if EnumDescription is provided.
+func (e *EnumDescription) values(tokens []string) []int32 {
+	res := make([]int32, 0, len(tokens))
+	for _, token := range tokens {
+		if n, err := strconv.ParseInt(token, 10, 32); err == nil {
+			res = append(res, int32(n))
+		} else if e != nil {
+			for _, enum := range e.Enums {
+				if enum.Name == token || enum.FullName == token || enum.Display == token {
+					res = append(res, enum.Value)
+					break
+				}
+			}
+		}
+	}
+	return res
+}
+
+func union(values []int32) int32 {
+	var res int32
+	for _, v := range values {
+		res |= v
+	}
+	return res
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type itemRulesKey struct {
+	rules  *ItemRules
+	parent *FieldMeta
+	ed     *EnumDescription
+}
+
+// itemRules caches FieldMeta of ItemRules
+var itemRules sync.Map
+
+// noItemRules is used for the enum items without the constraints
+var noItemRules = &ItemRules{}
+
+// fieldMeta returns FieldMeta with the item constraints of the parent field,
+// or nil if the rules are not provided, and the item is not enum.
+func (x *ItemRules) fieldMeta(parent *FieldMeta, ed *EnumDescription) *FieldMeta {
+	if x == nil {
+		if ed == nil {
+			return nil
+		}
+		x = noItemRules
+	}
+	key := itemRulesKey{rules: x, parent: parent, ed: ed}
+	if fm, ok := itemRules.Load(key); ok {
+		return fm.(*FieldMeta)
+	}
+	fm := &FieldMeta{
+		Name:            parent.Name,
+		Display:         parent.Display,
+		Min:             x.Min,
+		Max:             x.Max,
+		Pattern:         x.Pattern,
+		Prefix:          x.Prefix,
+		Suffix:          x.Suffix,
+		Format:          x.Format,
+		In:              x.In,
+		NotIn:           x.NotIn,
+		Range:           x.Range,
+		EnumDescription: ed,
+	}
+	itemRules.Store(key, fm)
+	return fm
+}
+
+// ItemMeta returns FieldMeta with es.api.items rules of the repeated field,
+// or nil if the items are not constrained.
+func (x *FieldMeta) ItemMeta() *FieldMeta {
+	return x.Items.fieldMeta(x, x.EnumDescription)
+}
+
+// KeyMeta returns FieldMeta with es.api.keys rules of the map field,
+// or nil if the keys are not constrained.
+func (x *FieldMeta) KeyMeta() *FieldMeta {
+	return x.Keys.fieldMeta(x, nil)
+}
+
+// validateItem checks es.api.items and es.api.keys options
+func (v *validation) validateItem(val protoreflect.Value, kind protoreflect.Kind, rules *FieldMeta, fieldPath string) error {
+	if rules == nil {
+		return nil
+	}
+	switch kind {
+	case protoreflect.StringKind:
+		s := val.String()
+		if err := v.validateLength(len(s), rules, fieldPath); err != nil {
+			return err
+		}
+		if err := v.validateString(s, rules, fieldPath); err != nil {
+			return err
+		}
+		return v.validateIn(s, rules, fieldPath)
+	case protoreflect.BytesKind:
+		return v.validateLength(len(val.Bytes()), rules, fieldPath)
+	case protoreflect.EnumKind:
+		return v.validateEnumIn(int32(val.Enum()), rules, fieldPath)
+	}
+	if err := v.validateRange(val, kind, rules, fieldPath); err != nil {
+		return err
+	}
+	return v.validateIntIn(val, kind, rules, fieldPath)
+}
+
+// validateUnique checks es.api.unique option
+func (v *validation) validateUnique(list protoreflect.List, kind protoreflect.Kind, field *FieldMeta, fieldPath string) error {
+	if kind == protoreflect.MessageKind || kind == protoreflect.GroupKind {
+		return nil
+	}
+	return v.validateUniqueKeys(list.Len(), func(i int) any {
+		return list.Get(i).Interface()
+	}, field, fieldPath)
+}
+
+// validateUniqueKeys reports each duplicate item once,
+// key returns the comparable value of the item.
+func (v *validation) validateUniqueKeys(n int, key func(i int) any, field *FieldMeta, fieldPath string) error {
+	seen := make(map[any]int, n)
+	for i := 0; i < n; i++ {
+		k := key(i)
+		if b, ok := k.([]byte); ok {
+			k = string(b)
+		}
+		first, ok := seen[k]
+		if !ok {
+			seen[k] = i
+			continue
+		}
+		elementPath := fmt.Sprintf("%s[%d]", fieldPath, i)
+		firstPath := fmt.Sprintf("%s[%d]", fieldPath, first)
+		vi := violation{
+			Field:  field,
+			Path:   elementPath,
+			Rule:   RuleUnique,
+			Actual: k,
+			Params: map[string]string{ParamFirst: firstPath},
+		}
+		if err := v.violate(vi, "%s: duplicate of %s", elementPath, firstPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"math"
+	"strconv"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// IntBound returns NumberBound with int64 value
+func IntBound(v int64, exclusive bool) *NumberBound {
+	return &NumberBound{Value: &NumberBound_Int{Int: v}, Exclusive: exclusive}
+}
+
+// UintBound returns NumberBound with uint64 value
+func UintBound(v uint64, exclusive bool) *NumberBound {
+	return &NumberBound{Value: &NumberBound_Uint{Uint: v}, Exclusive: exclusive}
+}
+
+// FloatBound returns NumberBound with float64 value
+func FloatBound(v float64, exclusive bool) *NumberBound {
+	return &NumberBound{Value: &NumberBound_Float{Float: v}, Exclusive: exclusive}
+}
+
+// Format returns the bound value as string
+func (x *NumberBound) Format() string {
+	switch v := x.GetValue().(type) {
+	case *NumberBound_Int:
+		return strconv.FormatInt(v.Int, 10)
+	case *NumberBound_Uint:
+		return strconv.FormatUint(v.Uint, 10)
+	case *NumberBound_Float:
+		return strconv.FormatFloat(v.Float, 'g', -1, 64)
+	}
+	return ""
+}
+
+// compare returns -1, 0 or +1, if the value is less, equal or greater than the bound,
+// ok is false if the value is not comparable, for example NaN.
+func (x *NumberBound) compare(val protoreflect.Value, kind protoreflect.Kind) (res int, ok bool) {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		i := val.Int()
+		switch b := x.GetValue().(type) {
+		case *NumberBound_Int:
+			return compareOrdered(i, b.Int), true
+		case *NumberBound_Uint:
+			if i < 0 {
+				return -1, true
+			}
+			return compareOrdered(uint64(i), b.Uint), true
+		case *NumberBound_Float:
+			return compareFloat(float64(i), b.Float)
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		u := val.Uint()
+		switch b := x.GetValue().(type) {
+		case *NumberBound_Int:
+			if b.Int < 0 {
+				return 1, true
+			}
+			return compareOrdered(u, uint64(b.Int)), true
+		case *NumberBound_Uint:
+			return compareOrdered(u, b.Uint), true
+		case *NumberBound_Float:
+			return compareFloat(float64(u), b.Float)
+		}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		f := val.Float()
+		switch b := x.GetValue().(type) {
+		case *NumberBound_Int:
+			return compareFloat(f, float64(b.Int))
+		case *NumberBound_Uint:
+			return compareFloat(f, float64(b.Uint))
+		case *NumberBound_Float:
+			return compareFloat(f, b.Float)
+		}
+	}
+	return 0, false
+}
+
+func compareOrdered[T int64 | uint64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat(a, b float64) (int, bool) {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return 0, false
+	}
+	switch {
+	case a < b:
+		return -1, true
+	case a > b:
+		return 1, true
+	}
+	return 0, true
+}
+
+func isNumberKind(kind protoreflect.Kind) bool {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind,
+		protoreflect.FloatKind, protoreflect.DoubleKind:
+		return true
+	}
+	return false
+}
+
+// validateRange checks es.api.range option
+func (v *validation) validateRange(val protoreflect.Value, kind protoreflect.Kind, field *FieldMeta, fieldPath string) error {
+	if field.Range == nil || !isNumberKind(kind) {
+		return nil
+	}
+	actual := val.Interface()
+
+	if lower := field.Range.GetMin(); lower.GetValue() != nil {
+		res, ok := lower.compare(val, kind)
+		if !ok || res < 0 || (res == 0 && lower.Exclusive) {
+			if err := v.violateRangeMin(field, fieldPath, actual); err != nil {
+				return err
+			}
+		}
+	}
+
+	if upper := field.Range.GetMax(); upper.GetValue() != nil {
+		res, ok := upper.compare(val, kind)
+		if !ok || res > 0 || (res == 0 && upper.Exclusive) {
+			return v.violateRangeMax(field, fieldPath, actual)
+		}
+	}
+	return nil
+}
+
+func (v *validation) violateRangeMin(field *FieldMeta, fieldPath string, actual any) error {
+	lower := field.Range.GetMin()
+	if lower.Exclusive {
+		return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleMin, Code: CodeGreaterThan, Limit: lower.Format(), Actual: actual}, "%s: must be greater than %s", fieldPath, lower.Format())
+	}
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleMin, Limit: lower.Format(), Actual: actual}, "%s: minimum value is %s", fieldPath, lower.Format())
+}
+
+func (v *validation) violateRangeMax(field *FieldMeta, fieldPath string, actual any) error {
+	upper := field.Range.GetMax()
+	if upper.Exclusive {
+		return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleMax, Code: CodeLessThan, Limit: upper.Format(), Actual: actual}, "%s: must be less than %s", fieldPath, upper.Format())
+	}
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleMax, Limit: upper.Format(), Actual: actual}, "%s: maximum value is %s", fieldPath, upper.Format())
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/effective-security/porto/xhttp/httperror"
+	"github.com/effective-security/xlog"
+	"github.com/google/cel-go/cel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// RuleExpr is the default rule reported in FieldViolation for MessageRule
+const RuleExpr = "expr"
+
+// compiledRule is MessageRule with the compiled CEL program
+type compiledRule struct {
+	*MessageRule
+	prg cel.Program
+}
+
+// compiledRules holds the rules compiled by MustCompileMessageRules
+// for the generated MessageDescription, the other descriptions
+// are not cached, to not grow with the descriptions created at runtime.
+var compiledRules sync.Map
+
+// CompileMessageRules compiles CEL expressions of the rules for the message
+// descriptor, the message is available in the expressions as `this`.
+func CompileMessageRules(desc protoreflect.MessageDescriptor, rules []*MessageRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	_, err := compileRules(desc, rules)
+	return err
+}
+
+// MustCompileMessageRules compiles the rules of MessageDescription for msg,
+// it is called by init of the generated code, and panics if a rule is invalid.
+func MustCompileMessageRules(md *MessageDescription, msg proto.Message) {
+	if len(md.Rules) == 0 {
+		return
+	}
+	rules, err := compileRules(msg.ProtoReflect().Descriptor(), md.Rules)
+	if err != nil {
+		panic(errors.WithMessagef(err, "invalid rules of %s", md.FullName))
+	}
+	compiledRules.Store(md, rules)
+}
+
+func compileRules(desc protoreflect.MessageDescriptor, rules []*MessageRule) ([]*compiledRule, error) {
+	env, err := cel.NewEnv(
+		cel.TypeDescs(desc.ParentFile()),
+		cel.Variable("this", cel.ObjectType(string(desc.FullName()))),
+		// allows to compare int and uint fields, for example size(this.IDs) <= this.Limit
+		cel.CrossTypeNumericComparisons(true),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	list := make([]*compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		ast, iss := env.Compile(rule.Expr)
+		if iss.Err() != nil {
+			return nil, errors.Errorf("failed to compile %q: %s", rule.Expr, iss.Err().Error())
+		}
+		if ast.OutputType() != cel.BoolType {
+			return nil, errors.Errorf("expression %q must return bool, but returns %s", rule.Expr, ast.OutputType())
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to create program %q", rule.Expr)
+		}
+		list = append(list, &compiledRule{MessageRule: rule, prg: prg})
+	}
+	return list, nil
+}
+
+// getCompiledRules returns the rules compiled at init,
+// or compiles the rules of MessageDescription created at runtime.
+func getCompiledRules(md *MessageDescription, msg protoreflect.Message) ([]*compiledRule, error) {
+	if rules, ok := compiledRules.Load(md); ok {
+		return rules.([]*compiledRule), nil
+	}
+	rules, err := compileRules(msg.Descriptor(), md.Rules)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid rules of %s", md.FullName)
+	}
+	return rules, nil
+}
+
+// validateRules evaluates the message-level rules,
+// the rule that can not be compiled or evaluated fails the validation with Internal error.
+func (v *validation) validateRules(msg protoreflect.Message, md *MessageDescription) error {
+	rules, err := getCompiledRules(md, msg)
+	if err != nil {
+		logger.ContextKV(v.ctx, xlog.ERROR,
+			"reason", "compile_rules",
+			"struct", md.Name,
+			"err", err,
+		)
+		return httperror.NewGrpcFromCtx(v.ctx, codes.Internal, "%s: failed to validate request", md.Name)
+	}
+	for _, rule := range rules {
+		out, _, err := rule.prg.Eval(map[string]any{"this": msg.Interface()})
+		if err != nil {
+			logger.ContextKV(v.ctx, xlog.ERROR,
+				"reason", "eval_rule",
+				"struct", md.Name,
+				"expr", rule.Expr,
+				"err", err,
+			)
+			return httperror.NewGrpcFromCtx(v.ctx, codes.Internal, "%s: failed to validate request", md.Name)
+		}
+		if ok, _ := out.Value().(bool); ok {
+			continue
+		}
+
+		id := rule.ID
+		if id == "" {
+			id = RuleExpr
+		}
+		msgText := rule.Message
+		if msgText == "" {
+			msgText = "failed rule: " + rule.Expr
+		}
+		vi := violation{
+			Rule:   id,
+			Code:   CodeRule,
+			Params: map[string]string{ParamRule: id, ParamExpr: rule.Expr},
+		}
+		if err := v.violate(vi, "%s", msgText); err != nil {
+			return err
+		}
+	}
+	return nil
+}
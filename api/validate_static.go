@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+
+	"github.com/effective-security/porto/xhttp/httperror"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Validation is the state of the static validator,
+// generated by protoc-gen-go-enum with static-validate option.
+// The checks are shared with ValidateRequest, to produce the same results.
+type Validation struct {
+	v *validation
+}
+
+// ValidateStatic validates req with the generated fn,
+// with the same semantics as ValidateRequest, but without traversing
+// the message fields with protoreflect.
+func ValidateStatic[T proto.Message](ctx context.Context, req T, md *MessageDescription, fn func(v *Validation, m T) error) error {
+	if md == nil {
+		return nil
+	}
+
+	msgReflect := req.ProtoReflect()
+	if !msgReflect.IsValid() {
+		return httperror.NewGrpcFromCtx(ctx, codes.InvalidArgument, "%s: is not a valid protobuf message", md.GetDisplayName())
+	}
+
+	return runValidation(ctx, msgReflect, md, func(v *validation) error {
+		return fn(&Validation{v: v}, req)
+	})
+}
+
+// Required reports the required field that is not set
+func (s *Validation) Required(field *FieldMeta, fieldPath string) error {
+	return s.v.violate(violation{Field: field, Path: fieldPath, Rule: RuleRequired}, "%s is required", fieldPath)
+}
+
+// IsResponse returns true, if the response is validated,
+// see WithResponseValidation
+func (s *Validation) IsResponse() bool {
+	return s.v.response
+}
+
+// OutputOnly reports OUTPUT_ONLY field, that is set in the request
+func (s *Validation) OutputOnly(field *FieldMeta, fieldPath string) error {
+	return s.v.violateOutputOnly(field, fieldPath)
+}
+
+// RequiredOr reports the field, when none of RequiredOr fields are set
+func (s *Validation) RequiredOr(field *FieldMeta, fieldPath string) error {
+	return s.v.violateRequiredOr(field, fieldPath)
+}
+
+// Count checks min_count and max_count of repeated and map fields
+func (s *Validation) Count(length int, field *FieldMeta, fieldPath string) error {
+	return s.v.validateCount(length, field, fieldPath)
+}
+
+// MinLength reports the string or bytes value shorter than min
+func (s *Validation) MinLength(field *FieldMeta, fieldPath string, length int) error {
+	return s.v.violateMinLength(field, fieldPath, length)
+}
+
+// MaxLength reports the string or bytes value longer than max
+func (s *Validation) MaxLength(field *FieldMeta, fieldPath string, length int) error {
+	return s.v.violateMaxLength(field, fieldPath, length)
+}
+
+// Prefix reports the string value without the prefix
+func (s *Validation) Prefix(field *FieldMeta, fieldPath string) error {
+	return s.v.violatePrefix(field, fieldPath)
+}
+
+// Suffix reports the string value without the suffix
+func (s *Validation) Suffix(field *FieldMeta, fieldPath string) error {
+	return s.v.violateSuffix(field, fieldPath)
+}
+
+// Pattern reports the string value, that does not match the pattern
+func (s *Validation) Pattern(field *FieldMeta, fieldPath string) error {
+	return s.v.violatePattern(field, fieldPath)
+}
+
+// Format reports the string value, that is not in the format
+func (s *Validation) Format(field *FieldMeta, fieldPath string) error {
+	return s.v.violateFormat(field, fieldPath)
+}
+
+// In reports the value, that is not one of es.api.in values
+func (s *Validation) In(field *FieldMeta, fieldPath string, actual any) error {
+	return s.v.violateIn(field, fieldPath, actual)
+}
+
+// NotIn reports the value, that is one of es.api.not_in values
+func (s *Validation) NotIn(field *FieldMeta, fieldPath string, actual any) error {
+	return s.v.violateNotIn(field, fieldPath, actual)
+}
+
+// Min reports the number less than es.api.min
+func (s *Validation) Min(field *FieldMeta, fieldPath string, actual any) error {
+	return s.v.violateMin(field, fieldPath, actual)
+}
+
+// Max reports the number greater than es.api.max
+func (s *Validation) Max(field *FieldMeta, fieldPath string, actual any) error {
+	return s.v.violateMax(field, fieldPath, actual)
+}
+
+// RangeMin reports the number out of the lower bound of es.api.range
+func (s *Validation) RangeMin(field *FieldMeta, fieldPath string, actual any) error {
+	return s.v.violateRangeMin(field, fieldPath, actual)
+}
+
+// RangeMax reports the number out of the upper bound of es.api.range
+func (s *Validation) RangeMax(field *FieldMeta, fieldPath string, actual any) error {
+	return s.v.violateRangeMax(field, fieldPath, actual)
+}
+
+// Enum reports the value, that is not defined by the enum
+func (s *Validation) Enum(field *FieldMeta, fieldPath string, num int32) error {
+	return s.v.violateEnum(field, fieldPath, num)
+}
+
+// EnumFlags reports the invalid flags of bitmask enum
+func (s *Validation) EnumFlags(field *FieldMeta, fieldPath string, num, invalid int32) error {
+	return s.v.violateEnumFlags(field, fieldPath, num, invalid)
+}
+
+// Timestamp checks es.api.time_range option of Timestamp
+func (s *Validation) Timestamp(field *FieldMeta, fieldPath string, sec, nanos int64) error {
+	return s.v.validateTimestamp(sec, nanos, field, fieldPath)
+}
+
+// Duration checks es.api.time_range option of Duration
+func (s *Validation) Duration(field *FieldMeta, fieldPath string, sec, nanos int64) error {
+	return s.v.validateDuration(sec, nanos, field, fieldPath)
+}
+
+// Nested checks the fields of the nested message,
+// that is defined in another package, and has no generated validator.
+func (s *Validation) Nested(msg proto.Message, field *FieldMeta, fieldPath string) error {
+	return s.v.validateReflectFields(msg.ProtoReflect(), field.Fields, fieldPath)
+}
+
+// Oneof reports the required oneof group that is not set
+func (s *Validation) Oneof(oneof *OneofMeta) error {
+	return s.v.violateOneof(oneof)
+}
+
+// ValidateUnique checks es.api.unique option of repeated field items
+func ValidateUnique[T any](s *Validation, items []T, field *FieldMeta, fieldPath string) error {
+	return s.v.validateUniqueKeys(len(items), func(i int) any {
+		var k any = items[i]
+		if e, ok := k.(protoreflect.Enum); ok {
+			// same as protoreflect.List item
+			return e.Number()
+		}
+		return k
+	}, field, fieldPath)
+}
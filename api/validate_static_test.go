@@ -0,0 +1,116 @@
+package api_test
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/effective-security/protoc-gen-go/api"
+	"github.com/effective-security/protoc-gen-go/e2e"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// the shared cases to verify, that the generated static validators
+// return the same results as ValidateRequest
+var staticValidationCases = []struct {
+	name string
+	msg  proto.Message
+	md   *api.MessageDescription
+}{
+	{name: "basic_nil", msg: (*e2e.Basic)(nil), md: e2e.Basic_MessageDescription},
+	{name: "basic_empty", msg: &e2e.Basic{}, md: e2e.Basic_MessageDescription},
+	{name: "basic_valid", msg: &e2e.Basic{Name: "12345678", Map: map[string]string{"k": "v"}, Values: []string{"a", "b"}}, md: e2e.Basic_MessageDescription},
+	{name: "basic_invalid", msg: &e2e.Basic{
+		Name:          "1",
+		Map:           map[string]string{"K": "v", "k": "v", "a": "v"},
+		Values:        []string{"a", "a", strings.Repeat("b", 65), "a"},
+		Statuses:      e2e.JobStatus_Enum(0x40),
+		ResourceTypes: e2e.ResourceType_Enum(100),
+		Created:       timestamppb.New(timestamppb.Now().AsTime().Add(time.Hour)),
+		B:             &e2e.Basic_Str{Str: ""},
+	}, md: e2e.Basic_MessageDescription},
+	{name: "basic_optional_zero", msg: &e2e.Basic{Name: "12345678", Map: map[string]string{"k": "v"}, Values: []string{"a"}, Priority: proto.Int32(0)}, md: e2e.Basic_MessageDescription},
+	{name: "basic_optional_set", msg: &e2e.Basic{Name: "12345678", Map: map[string]string{"k": "v"}, Values: []string{"a"}, Priority: proto.Int32(5)}, md: e2e.Basic_MessageDescription},
+	{name: "basic_output_only", msg: &e2e.Basic{A: "server", Name: "1"}, md: e2e.Basic_MessageDescription},
+	{name: "list_empty", msg: &e2e.ListAnnotationsRequest{}, md: e2e.ListAnnotationsRequest_MessageDescription},
+	{name: "list_valid", msg: &e2e.ListAnnotationsRequest{
+		Name:       "test",
+		AssetID:    "123456789",
+		ResourceID: "123456789",
+		Display:    "testaaaaaaaa",
+		Filter:     &e2e.ListAnnotationsRequest_Type{Type: e2e.AnnotationType_Foo},
+	}, md: e2e.ListAnnotationsRequest_MessageDescription},
+	{name: "list_invalid", msg: &e2e.ListAnnotationsRequest{
+		ResourceID: "1",
+		AssetIDs:   []string{"1", "2", "3"},
+		Display:    "t",
+		Limit:      1,
+		Filter:     &e2e.ListAnnotationsRequest_Category{Category: e2e.AnnotationCategory_Enum(0x4000)},
+	}, md: e2e.ListAnnotationsRequest_MessageDescription},
+	{name: "annotation_empty", msg: &e2e.Annotation{}, md: e2e.Annotation_MessageDescription},
+	{name: "annotation_valid", msg: &e2e.Annotation{
+		ID:          "123456789",
+		Name:        "name",
+		Type:        e2e.AnnotationType_Foo,
+		Map:         map[string]string{"k": "v"},
+		Metadata:    []*e2e.KVPair{{Key: "k", Value: "v"}},
+		Basic:       &e2e.Basic{Name: "12345678", Map: map[string]string{"k": "v"}, Values: []string{"a"}},
+		FloatValue:  2,
+		BytesValue:  []byte("12"),
+		Uint64Value: 1,
+		Int64Value:  1,
+		Uint32Value: 2,
+		Int32Value:  2,
+		Strings:     []string{"a"},
+		Types:       []e2e.AnnotationType_Enum{e2e.AnnotationType_Foo},
+		Hashes:      []int64{0, 9999999999},
+	}, md: e2e.Annotation_MessageDescription},
+	{name: "annotation_invalid", msg: &e2e.Annotation{
+		ID:          "1",
+		Name:        strings.Repeat("n", 13),
+		Type:        e2e.AnnotationType_Enum(7),
+		Metadata:    []*e2e.KVPair{{Key: "k"}, nil, {Value: "v"}},
+		Basic:       &e2e.Basic{Name: "1", A: "server", Map: map[string]string{"K": "v"}, Statuses: e2e.JobStatus_Enum(0x40)},
+		FloatValue:  float32(math.NaN()),
+		BytesValue:  []byte("1"),
+		Uint64Value: 11,
+		Int64Value:  -1,
+		Uint32Value: 1,
+		Int32Value:  11,
+		Strings:     []string{"a", "b", "c", "d"},
+		Types:       []e2e.AnnotationType_Enum{e2e.AnnotationType_Foo, e2e.AnnotationType_Enum(9)},
+		Hashes:      []int64{-1, 10000000000, 5},
+	}, md: e2e.Annotation_MessageDescription},
+}
+
+func TestValidateStatic(t *testing.T) {
+	// populate the nested fields, to validate the nested messages
+	e2e.GetMessageDescriptions()
+
+	for _, tc := range staticValidationCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, ctx := range []context.Context{
+				context.Background(),
+				api.WithAllViolations(context.Background()),
+				api.WithResponseValidation(api.WithAllViolations(context.Background())),
+			} {
+				exp := api.ValidateRequest(ctx, tc.msg, tc.md)
+				err := tc.msg.(api.Validator).Validate(ctx)
+				if exp == nil {
+					assert.NoError(t, err)
+					continue
+				}
+				assert.EqualError(t, err, exp.Error())
+				assert.ElementsMatch(t, api.FieldViolations(exp), api.FieldViolations(err))
+			}
+		})
+	}
+
+	assert.NoError(t, api.ValidateStatic(context.Background(), &e2e.Basic{}, nil, func(*api.Validation, *e2e.Basic) error {
+		panic("not called")
+	}))
+}
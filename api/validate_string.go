@@ -0,0 +1,130 @@
+package api
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// String formats supported by es.api.format option
+const (
+	FormatEmail    = "email"
+	FormatURI      = "uri"
+	FormatHostname = "hostname"
+	FormatIP       = "ip"
+	FormatIPv4     = "ipv4"
+	FormatIPv6     = "ipv6"
+	FormatUUID     = "uuid"
+	FormatULID     = "ulid"
+)
+
+var (
+	hostnameRegex = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+	uuidRegex     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	ulidRegex     = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{25}$`)
+)
+
+// stringFormats maps es.api.format to the check function
+var stringFormats = map[string]func(string) bool{
+	FormatEmail: func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	},
+	FormatURI: func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	},
+	FormatHostname: func(s string) bool {
+		return len(s) <= 253 && hostnameRegex.MatchString(strings.TrimSuffix(s, "."))
+	},
+	FormatIP: func(s string) bool {
+		return net.ParseIP(s) != nil
+	},
+	FormatIPv4: func(s string) bool {
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil
+	},
+	FormatIPv6: func(s string) bool {
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() == nil
+	},
+	FormatUUID: uuidRegex.MatchString,
+	FormatULID: ulidRegex.MatchString,
+}
+
+// IsStringFormat returns true if the format is supported by es.api.format
+func IsStringFormat(format string) bool {
+	_, ok := stringFormats[format]
+	return ok
+}
+
+// IsValidStringFormat returns true if s is in the format,
+// it panics if the format is not supported.
+func IsValidStringFormat(format, s string) bool {
+	check, ok := stringFormats[format]
+	if !ok {
+		panic("unsupported format: " + format)
+	}
+	return check(s)
+}
+
+// patterns caches compiled es.api.pattern options,
+// as the patterns are defined by static descriptions,
+// each one is compiled once.
+var patterns sync.Map
+
+// compilePattern returns the compiled pattern,
+// it panics if the pattern is invalid.
+func compilePattern(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(pattern)
+	patterns.Store(pattern, re)
+	return re
+}
+
+func (v *validation) validateString(s string, field *FieldMeta, fieldPath string) error {
+	if s == "" {
+		// empty values are checked by required option
+		return nil
+	}
+	if field.Prefix != "" && !strings.HasPrefix(s, field.Prefix) {
+		if err := v.violatePrefix(field, fieldPath); err != nil {
+			return err
+		}
+	}
+	if field.Suffix != "" && !strings.HasSuffix(s, field.Suffix) {
+		if err := v.violateSuffix(field, fieldPath); err != nil {
+			return err
+		}
+	}
+	if field.Pattern != "" && !compilePattern(field.Pattern).MatchString(s) {
+		if err := v.violatePattern(field, fieldPath); err != nil {
+			return err
+		}
+	}
+	if field.Format != "" && !IsValidStringFormat(field.Format, s) {
+		return v.violateFormat(field, fieldPath)
+	}
+	return nil
+}
+
+func (v *validation) violatePrefix(field *FieldMeta, fieldPath string) error {
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RulePrefix, Limit: field.Prefix}, "%s: must start with %q", fieldPath, field.Prefix)
+}
+
+func (v *validation) violateSuffix(field *FieldMeta, fieldPath string) error {
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleSuffix, Limit: field.Suffix}, "%s: must end with %q", fieldPath, field.Suffix)
+}
+
+func (v *validation) violatePattern(field *FieldMeta, fieldPath string) error {
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RulePattern, Limit: field.Pattern}, "%s: must match pattern %q", fieldPath, field.Pattern)
+}
+
+func (v *validation) violateFormat(field *FieldMeta, fieldPath string) error {
+	return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleFormat, Limit: field.Format}, "%s: must be a valid %s", fieldPath, field.Format)
+}
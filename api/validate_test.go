@@ -2,10 +2,25 @@ package api_test
 
 import (
 	"context"
+	"encoding/json"
+	"math"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/cockroachdb/errors"
+	"github.com/effective-security/porto/xhttp/httperror"
+	"github.com/effective-security/protoc-gen-go/api"
 	"github.com/effective-security/protoc-gen-go/e2e"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestValidateRequest_ListAnnotationsRequest(t *testing.T) {
@@ -563,6 +578,34 @@ func TestValidateRequest_Annotation(t *testing.T) {
 			},
 			exp: "",
 		},
+		{
+			name: "invalid_type",
+			msg: &e2e.Annotation{
+				ID:   "123456789",
+				Name: "test",
+				Type: 9999,
+				Map:  map[string]string{"test": "test"},
+				Metadata: []*e2e.KVPair{
+					{
+						Key:   "test",
+						Value: "test",
+					},
+				},
+				Basic: &e2e.Basic{
+					Map:    map[string]string{"test": "test"},
+					Name:   "testaaaaaaaaaaaaaaa",
+					Values: []string{"test"},
+				},
+				FloatValue:  2.5,
+				BytesValue:  []byte("test"),
+				Uint64Value: 10,
+				Int64Value:  10,
+				Uint32Value: 10,
+				Int32Value:  10,
+				Strings:     []string{"test", "test2"},
+			},
+			exp: "bad_request: Type: invalid enum value: 9999",
+		},
 	}
 
 	for _, tc := range tcases {
@@ -607,3 +650,949 @@ func TestValidateRequest_Basic(t *testing.T) {
 		})
 	}
 }
+
+type failingValidator struct {
+	err error
+}
+
+func (v failingValidator) Validate(context.Context) error {
+	if v.err != nil {
+		return v.err
+	}
+	return errors.New("failed")
+}
+
+func TestValidateMessage(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, api.ValidateMessage(ctx, &emptypb.Empty{}))
+
+	err := api.ValidateMessage(ctx, &e2e.AnnotationRequest{})
+	assert.EqualError(t, err, "bad_request: ID is required")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	err = api.ValidateMessage(ctx, failingValidator{})
+	assert.EqualError(t, err, "bad_request: failed")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the server fault is not reported as the invalid request
+	internal := httperror.NewGrpc(codes.Internal, "failed to validate request")
+	err = api.ValidateMessage(ctx, failingValidator{err: internal})
+	assert.Same(t, internal, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	md := &api.MessageDescription{
+		Name:     "Annotation",
+		FullName: "e2e.Annotation",
+		Rules:    []*api.MessageRule{{Expr: "this.Unknown > 0"}},
+	}
+	err = api.ValidateMessage(ctx, messageValidator{msg: &e2e.Annotation{}, md: md})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+// messageValidator validates msg with md
+type messageValidator struct {
+	msg proto.Message
+	md  *api.MessageDescription
+}
+
+func (v messageValidator) Validate(ctx context.Context) error {
+	return api.ValidateRequest(ctx, v.msg, v.md)
+}
+
+func TestValidateRequest_AllViolations(t *testing.T) {
+	ctx := api.WithAllViolations(context.Background())
+
+	err := (&e2e.Basic{Name: "short"}).Validate(ctx)
+	assert.EqualError(t, err, "bad_request: map: minimum count is 1; name: minimum length is 8; values: minimum count is 1")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	exp := []*api.FieldViolation{
+		{Field: "map", Rule: api.RuleMinCount, Code: api.CodeMinCount, Display: "map", Limit: "1", Actual: "0", Description: "map: minimum count is 1"},
+		{Field: "name", Rule: api.RuleMinLength, Code: api.CodeMinLength, Display: "name", Limit: "8", Actual: "5", Description: "name: minimum length is 8"},
+		{Field: "values", Rule: api.RuleMinCount, Code: api.CodeMinCount, Display: "values", Limit: "1", Actual: "0", Description: "values: minimum count is 1"},
+	}
+	assert.Equal(t, exp, api.FieldViolations(err))
+
+	js, jerr := json.Marshal(err)
+	require.NoError(t, jerr)
+	assert.JSONEq(t, `{"code":"bad_request","message":"map: minimum count is 1; name: minimum length is 8; values: minimum count is 1","field_violations":[
+		{"field":"map","rule":"min_count","code":"MIN_COUNT","display":"map","limit":"1","actual":"0","description":"map: minimum count is 1"},
+		{"field":"name","rule":"min_length","code":"MIN_LEN","display":"name","limit":"8","actual":"5","description":"name: minimum length is 8"},
+		{"field":"values","rule":"min_count","code":"MIN_COUNT","display":"values","limit":"1","actual":"0","description":"values: minimum count is 1"}]}`, string(js))
+
+	// gRPC clients receive errdetails.BadRequest and errdetails.ErrorInfo
+	st := status.Convert(err)
+	assert.Equal(t, exp, api.FieldViolations(st.Err()))
+
+	assert.NoError(t, (&e2e.Basic{Name: "12345678", Map: map[string]string{"k": "v"}, Values: []string{"1"}}).Validate(ctx))
+	assert.Nil(t, api.FieldViolations(errors.New("failed")))
+}
+
+func TestValidateRequest_PanicPolicy(t *testing.T) {
+	ctx := context.Background()
+	// malformed description causes panic
+	md := &api.MessageDescription{Name: "Basic", Fields: []*api.FieldMeta{nil}}
+	req := &e2e.Basic{}
+
+	var panics []string
+	api.SetValidationPanicHook(func(_ context.Context, name string, _ any) {
+		panics = append(panics, name)
+	})
+	defer api.SetValidationPanicHook(nil)
+	defer api.SetValidationPanicPolicy(api.PanicFailOpen)
+
+	assert.NoError(t, api.ValidateRequest(ctx, req, md))
+
+	api.SetValidationPanicPolicy(api.PanicFailClosed)
+	err := api.ValidateRequest(ctx, req, md)
+	assert.EqualError(t, err, "unexpected: Basic: failed to validate request")
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	api.SetValidationPanicPolicy(api.PanicRepanic)
+	assert.Panics(t, func() {
+		_ = api.ValidateRequest(ctx, req, md)
+	})
+
+	assert.Equal(t, []string{"Basic", "Basic", "Basic"}, panics)
+}
+
+func TestValidateRequest_StringConstraints(t *testing.T) {
+	ctx := context.Background()
+	md := &api.MessageDescription{
+		Name: "ListAnnotationsRequest",
+		Fields: []*api.FieldMeta{
+			{Name: "Name", Prefix: "ann-", Suffix: "-v1"},
+			{Name: "AssetID", Pattern: `^[0-9]+$`},
+			{Name: "ResourceID", Format: api.FormatUUID},
+			{Name: "Display", Format: api.FormatEmail},
+		},
+	}
+
+	tcases := []struct {
+		name string
+		msg  *e2e.ListAnnotationsRequest
+		exp  string
+	}{
+		{
+			name: "empty",
+			msg:  &e2e.ListAnnotationsRequest{},
+		},
+		{
+			name: "valid",
+			msg: &e2e.ListAnnotationsRequest{
+				Name:       "ann-test-v1",
+				AssetID:    "123",
+				ResourceID: "9b2e6e02-3e0c-4bd1-a5a4-6b3e8f1c2d4a",
+				Display:    "user@example.com",
+			},
+		},
+		{
+			name: "prefix",
+			msg:  &e2e.ListAnnotationsRequest{Name: "test-v1"},
+			exp:  `bad_request: Name: must start with "ann-"`,
+		},
+		{
+			name: "suffix",
+			msg:  &e2e.ListAnnotationsRequest{Name: "ann-test"},
+			exp:  `bad_request: Name: must end with "-v1"`,
+		},
+		{
+			name: "pattern",
+			msg:  &e2e.ListAnnotationsRequest{AssetID: "12a"},
+			exp:  `bad_request: AssetID: must match pattern "^[0-9]+$"`,
+		},
+		{
+			name: "uuid",
+			msg:  &e2e.ListAnnotationsRequest{ResourceID: "9b2e6e02"},
+			exp:  "bad_request: ResourceID: must be a valid uuid",
+		},
+		{
+			name: "email",
+			msg:  &e2e.ListAnnotationsRequest{Display: "User <user@example.com>"},
+			exp:  "bad_request: Display: must be a valid email",
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := api.ValidateRequest(ctx, tc.msg, md)
+			if tc.exp == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.exp)
+			}
+		})
+	}
+
+	err := api.ValidateRequest(api.WithAllViolations(ctx), &e2e.ListAnnotationsRequest{Name: "test"}, md)
+	violations := api.FieldViolations(err)
+	require.Len(t, violations, 2)
+	assert.Equal(t, api.RulePrefix, violations[0].Rule)
+	assert.Equal(t, "ann-", violations[0].Limit)
+	assert.Equal(t, api.RuleSuffix, violations[1].Rule)
+}
+
+func TestIsValidStringFormat(t *testing.T) {
+	tcases := []struct {
+		format string
+		value  string
+		exp    bool
+	}{
+		{api.FormatEmail, "user@example.com", true},
+		{api.FormatEmail, "user", false},
+		{api.FormatURI, "https://example.com/path?q=1", true},
+		{api.FormatURI, "/path", false},
+		{api.FormatHostname, "api.example.com", true},
+		{api.FormatHostname, "localhost", true},
+		{api.FormatHostname, "-invalid.com", false},
+		{api.FormatHostname, "in valid", false},
+		{api.FormatIP, "10.0.0.1", true},
+		{api.FormatIP, "::1", true},
+		{api.FormatIP, "10.0.0", false},
+		{api.FormatIPv4, "10.0.0.1", true},
+		{api.FormatIPv4, "::1", false},
+		{api.FormatIPv6, "::1", true},
+		{api.FormatIPv6, "10.0.0.1", false},
+		{api.FormatUUID, "9B2E6E02-3E0C-4BD1-A5A4-6B3E8F1C2D4A", true},
+		{api.FormatUUID, "9b2e6e023e0c4bd1a5a46b3e8f1c2d4a", false},
+		{api.FormatULID, "01ARZ3NDEKTSV4RRFFQ69G5FAV", true},
+		{api.FormatULID, "81ARZ3NDEKTSV4RRFFQ69G5FAV", false},
+		{api.FormatULID, "01ARZ3NDEKTSV4RRFFQ69G5FAU", false},
+	}
+	for _, tc := range tcases {
+		assert.Equal(t, tc.exp, api.IsValidStringFormat(tc.format, tc.value), "%s: %s", tc.format, tc.value)
+	}
+
+	assert.True(t, api.IsStringFormat(api.FormatULID))
+	assert.False(t, api.IsStringFormat("phone"))
+	assert.Panics(t, func() { api.IsValidStringFormat("phone", "1") })
+}
+
+func TestValidateRequest_Enum(t *testing.T) {
+	ctx := context.Background()
+	bitmask := &api.EnumDescription{
+		Name:      "Flags",
+		IsBitmask: true,
+		Enums: []*api.EnumMeta{
+			{Name: "None", Value: 0},
+			{Name: "A", Value: 1},
+			{Name: "C", Value: 4},
+		},
+	}
+
+	tcases := []struct {
+		name  string
+		field *api.FieldMeta
+		msg   *e2e.Annotation
+		exp   string
+	}{
+		{
+			name:  "defined",
+			field: &api.FieldMeta{Name: "Type", EnumDescription: e2e.AnnotationType_Enum_EnumDescription},
+			msg:   &e2e.Annotation{Type: e2e.AnnotationType_Foo},
+		},
+		{
+			name:  "undefined",
+			field: &api.FieldMeta{Name: "Type", EnumDescription: e2e.AnnotationType_Enum_EnumDescription},
+			msg:   &e2e.Annotation{Type: 9999},
+			exp:   "bad_request: Type: invalid enum value: 9999",
+		},
+		{
+			name:  "list",
+			field: &api.FieldMeta{Name: "Types", EnumDescription: e2e.AnnotationType_Enum_EnumDescription},
+			msg:   &e2e.Annotation{Types: []e2e.AnnotationType_Enum{e2e.AnnotationType_Bar, 3}},
+			exp:   "bad_request: Types[1]: invalid enum value: 3",
+		},
+		{
+			name:  "in",
+			field: &api.FieldMeta{Name: "Type", EnumDescription: e2e.AnnotationType_Enum_EnumDescription, In: []string{"Foo"}},
+			msg:   &e2e.Annotation{Type: e2e.AnnotationType_Bar},
+			exp:   "bad_request: Type: must be one of: Foo",
+		},
+		{
+			name:  "in_number",
+			field: &api.FieldMeta{Name: "Type", EnumDescription: e2e.AnnotationType_Enum_EnumDescription, In: []string{"1", "Foo"}},
+			msg:   &e2e.Annotation{Type: e2e.AnnotationType_Bar},
+		},
+		{
+			name:  "not_in",
+			field: &api.FieldMeta{Name: "Type", EnumDescription: e2e.AnnotationType_Enum_EnumDescription, NotIn: []string{"0"}},
+			msg:   &e2e.Annotation{},
+			exp:   "bad_request: Type: must not be one of: 0",
+		},
+		{
+			name:  "bitmask",
+			field: &api.FieldMeta{Name: "Type", EnumDescription: bitmask},
+			msg:   &e2e.Annotation{Type: 5},
+		},
+		{
+			name:  "bitmask_undefined",
+			field: &api.FieldMeta{Name: "Type", EnumDescription: bitmask},
+			msg:   &e2e.Annotation{Type: 7},
+			exp:   "bad_request: Type: invalid enum flags: 2",
+		},
+		{
+			name:  "bitmask_in",
+			field: &api.FieldMeta{Name: "Type", EnumDescription: bitmask, In: []string{"A"}},
+			msg:   &e2e.Annotation{Type: 5},
+			exp:   "bad_request: Type: must be one of: A",
+		},
+		{
+			name:  "bitmask_not_in",
+			field: &api.FieldMeta{Name: "Type", EnumDescription: bitmask, NotIn: []string{"C"}},
+			msg:   &e2e.Annotation{Type: 5},
+			exp:   "bad_request: Type: must not be one of: C",
+		},
+		{
+			name:  "string_in",
+			field: &api.FieldMeta{Name: "Name", In: []string{"a", "b"}},
+			msg:   &e2e.Annotation{Name: "c"},
+			exp:   "bad_request: Name: must be one of: a,b",
+		},
+		{
+			name:  "int_not_in",
+			field: &api.FieldMeta{Name: "Int32Value", NotIn: []string{"3"}},
+			msg:   &e2e.Annotation{Int32Value: 3},
+			exp:   "bad_request: Int32Value: must not be one of: 3",
+		},
+		{
+			name:  "uint_in",
+			field: &api.FieldMeta{Name: "Uint64Value", In: []string{"1", "2"}},
+			msg:   &e2e.Annotation{Uint64Value: 2},
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := &api.MessageDescription{Name: "Annotation", Fields: []*api.FieldMeta{tc.field}}
+			err := api.ValidateRequest(ctx, tc.msg, md)
+			if tc.exp == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.exp)
+			}
+		})
+	}
+}
+
+func TestValidateRequest_Range(t *testing.T) {
+	ctx := context.Background()
+
+	tcases := []struct {
+		name  string
+		field *api.FieldMeta
+		msg   *e2e.Annotation
+		exp   string
+	}{
+		{
+			name:  "int64_min_zero",
+			field: &api.FieldMeta{Name: "Int64Value", Range: &api.NumberRange{Min: api.IntBound(0, false)}},
+			msg:   &e2e.Annotation{Int64Value: -1},
+			exp:   "bad_request: Int64Value: minimum value is 0",
+		},
+		{
+			name:  "int64_large",
+			field: &api.FieldMeta{Name: "Int64Value", Range: &api.NumberRange{Max: api.IntBound(1<<40, false)}},
+			msg:   &e2e.Annotation{Int64Value: 1<<40 + 1},
+			exp:   "bad_request: Int64Value: maximum value is 1099511627776",
+		},
+		{
+			name:  "uint64_large",
+			field: &api.FieldMeta{Name: "Uint64Value", Range: &api.NumberRange{Min: api.UintBound(1<<63, false)}},
+			msg:   &e2e.Annotation{Uint64Value: 1 << 63},
+		},
+		{
+			name:  "uint64_negative_bound",
+			field: &api.FieldMeta{Name: "Uint64Value", Range: &api.NumberRange{Min: api.IntBound(-1, true)}},
+			msg:   &e2e.Annotation{},
+		},
+		{
+			name:  "float_exclusive",
+			field: &api.FieldMeta{Name: "FloatValue", Range: &api.NumberRange{Min: api.FloatBound(0, true), Max: api.FloatBound(1.5, false)}},
+			msg:   &e2e.Annotation{},
+			exp:   "bad_request: FloatValue: must be greater than 0",
+		},
+		{
+			name:  "float_max",
+			field: &api.FieldMeta{Name: "FloatValue", Range: &api.NumberRange{Min: api.FloatBound(0, true), Max: api.FloatBound(1.5, false)}},
+			msg:   &e2e.Annotation{FloatValue: 1.75},
+			exp:   "bad_request: FloatValue: maximum value is 1.5",
+		},
+		{
+			name:  "float_nan",
+			field: &api.FieldMeta{Name: "FloatValue", Range: &api.NumberRange{Max: api.FloatBound(1.5, false)}},
+			msg:   &e2e.Annotation{FloatValue: float32(math.NaN())},
+			exp:   "bad_request: FloatValue: maximum value is 1.5",
+		},
+		{
+			name:  "int_float_bound",
+			field: &api.FieldMeta{Name: "Int32Value", Range: &api.NumberRange{Max: api.FloatBound(2.5, false)}},
+			msg:   &e2e.Annotation{Int32Value: 2},
+		},
+		{
+			name:  "generated",
+			field: e2e.Annotation_MessageDescription.FindField("Hashes"),
+			msg:   &e2e.Annotation{Hashes: []int64{0, 10000000000}},
+			exp:   "bad_request: Hashes[1]: must be less than 10000000000",
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := &api.MessageDescription{Name: "Annotation", Fields: []*api.FieldMeta{tc.field}}
+			err := api.ValidateRequest(ctx, tc.msg, md)
+			if tc.exp == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.exp)
+			}
+		})
+	}
+}
+
+func TestValidateRequest_TimeRange(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	past := timestamppb.New(now.Add(-time.Hour))
+	future := timestamppb.New(now.Add(time.Hour))
+
+	basicFields := func(tr *api.TimeRange) *api.MessageDescription {
+		return &api.MessageDescription{Name: "Basic", Fields: []*api.FieldMeta{{Name: "created", TimeRange: tr}}}
+	}
+
+	tcases := []struct {
+		name string
+		msg  proto.Message
+		md   *api.MessageDescription
+		exp  string
+	}{
+		{
+			name: "generated_lt_now",
+			msg:  &e2e.Basic{Created: past},
+			md:   e2e.Basic_MessageDescription,
+			exp:  "bad_request: map: minimum count is 1",
+		},
+		{
+			name: "generated_future",
+			msg:  &e2e.Basic{Created: future, Map: map[string]string{"k": "v"}},
+			md:   e2e.Basic_MessageDescription,
+			exp:  "bad_request: created: must be in the past",
+		},
+		{
+			name: "absent",
+			msg:  &e2e.Basic{},
+			md:   basicFields(&api.TimeRange{GtNow: true}),
+		},
+		{
+			name: "gt_now",
+			msg:  &e2e.Basic{Created: past},
+			md:   basicFields(&api.TimeRange{GtNow: true}),
+			exp:  "bad_request: created: must be in the future",
+		},
+		{
+			name: "not_before_now",
+			msg:  &e2e.Basic{Created: past},
+			md:   basicFields(&api.TimeRange{NotBeforeNow: true}),
+			exp:  "bad_request: created: must not be before now",
+		},
+		{
+			name: "within",
+			msg:  &e2e.Basic{Created: future},
+			md:   basicFields(&api.TimeRange{Within: "2h"}),
+		},
+		{
+			name: "not_within",
+			msg:  &e2e.Basic{Created: timestamppb.New(now.Add(-48 * time.Hour))},
+			md:   basicFields(&api.TimeRange{Within: "24h"}),
+			exp:  "bad_request: created: must be within 24h from now",
+		},
+		{
+			name: "min_duration",
+			msg:  &errdetails.RetryInfo{RetryDelay: durationpb.New(500 * time.Millisecond)},
+			md: &api.MessageDescription{Name: "RetryInfo", Fields: []*api.FieldMeta{
+				{Name: "retry_delay", TimeRange: &api.TimeRange{MinDuration: "1s", MaxDuration: "1m"}},
+			}},
+			exp: "bad_request: retry_delay: minimum duration is 1s",
+		},
+		{
+			name: "max_duration",
+			msg:  &errdetails.RetryInfo{RetryDelay: durationpb.New(time.Hour)},
+			md: &api.MessageDescription{Name: "RetryInfo", Fields: []*api.FieldMeta{
+				{Name: "retry_delay", TimeRange: &api.TimeRange{MinDuration: "1s", MaxDuration: "1m"}},
+			}},
+			exp: "bad_request: retry_delay: maximum duration is 1m",
+		},
+		{
+			name: "max_duration_overflow",
+			msg:  &errdetails.RetryInfo{RetryDelay: &durationpb.Duration{Seconds: 315576000000}},
+			md: &api.MessageDescription{Name: "RetryInfo", Fields: []*api.FieldMeta{
+				{Name: "retry_delay", TimeRange: &api.TimeRange{MinDuration: "1s", MaxDuration: "1m"}},
+			}},
+			exp: "bad_request: retry_delay: maximum duration is 1m",
+		},
+		{
+			name: "min_duration_overflow",
+			msg:  &errdetails.RetryInfo{RetryDelay: &durationpb.Duration{Seconds: -315576000000, Nanos: -999999999}},
+			md: &api.MessageDescription{Name: "RetryInfo", Fields: []*api.FieldMeta{
+				{Name: "retry_delay", TimeRange: &api.TimeRange{MinDuration: "-1m", MaxDuration: "1m"}},
+			}},
+			exp: "bad_request: retry_delay: minimum duration is -1m",
+		},
+		{
+			name: "negative_duration",
+			msg:  &errdetails.RetryInfo{RetryDelay: &durationpb.Duration{Seconds: -1, Nanos: -500000000}},
+			md: &api.MessageDescription{Name: "RetryInfo", Fields: []*api.FieldMeta{
+				{Name: "retry_delay", TimeRange: &api.TimeRange{MinDuration: "-1.5s", MaxDuration: "-1s"}},
+			}},
+		},
+		{
+			name: "nanos",
+			msg:  &errdetails.RetryInfo{RetryDelay: &durationpb.Duration{Seconds: 1, Nanos: 1}},
+			md: &api.MessageDescription{Name: "RetryInfo", Fields: []*api.FieldMeta{
+				{Name: "retry_delay", TimeRange: &api.TimeRange{MaxDuration: "1s"}},
+			}},
+			exp: "bad_request: retry_delay: maximum duration is 1s",
+		},
+		{
+			name: "duration",
+			msg:  &errdetails.RetryInfo{RetryDelay: durationpb.New(time.Second)},
+			md: &api.MessageDescription{Name: "RetryInfo", Fields: []*api.FieldMeta{
+				{Name: "retry_delay", TimeRange: &api.TimeRange{MinDuration: "1s", MaxDuration: "1m"}},
+			}},
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := api.ValidateRequest(ctx, tc.msg, tc.md)
+			if tc.exp == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.exp)
+			}
+		})
+	}
+
+	err := api.ValidateRequest(api.WithAllViolations(ctx), &e2e.Basic{Created: past}, basicFields(&api.TimeRange{GtNow: true, Within: "1m"}))
+	violations := api.FieldViolations(err)
+	require.Len(t, violations, 2)
+	assert.Equal(t, api.RuleGtNow, violations[0].Rule)
+	assert.Equal(t, api.RuleWithin, violations[1].Rule)
+	assert.Equal(t, "1m", violations[1].Limit)
+
+	overflow := &api.MessageDescription{Name: "RetryInfo", Fields: []*api.FieldMeta{
+		{Name: "retry_delay", TimeRange: &api.TimeRange{MaxDuration: "1m"}},
+	}}
+	err = api.ValidateRequest(api.WithAllViolations(ctx), &errdetails.RetryInfo{RetryDelay: &durationpb.Duration{Seconds: 315576000000}}, overflow)
+	violations = api.FieldViolations(err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "315576000000.000000000s", violations[0].Actual)
+}
+
+func TestValidateRequest_Rules(t *testing.T) {
+	ctx := context.Background()
+
+	req := &e2e.ListAnnotationsRequest{
+		Name:       "test",
+		AssetID:    "123456789",
+		ResourceID: "123456789",
+		AssetIDs:   []string{"123456789", "223456789"},
+		Display:    "testaaaaaaaa",
+		Limit:      1,
+	}
+	assert.EqualError(t, req.Validate(ctx), "bad_request: AssetIDs must not exceed Limit")
+	req.Limit = 2
+	assert.NoError(t, req.Validate(ctx))
+
+	md := &api.MessageDescription{
+		Name:     "Annotation",
+		FullName: "e2e.Annotation",
+		Fields:   []*api.FieldMeta{{Name: "ID", Required: true}},
+		Rules: []*api.MessageRule{
+			{Expr: "this.Int32Value < this.Int64Value"},
+			{ID: "strings", Expr: "this.Strings.all(s, s.startsWith(this.Name))", Message: "Strings must start with Name"},
+		},
+	}
+	assert.NoError(t, api.ValidateRequest(ctx, &e2e.Annotation{ID: "1", Name: "a", Int64Value: 1, Strings: []string{"ab"}}, md))
+
+	err := api.ValidateRequest(ctx, &e2e.Annotation{ID: "1", Name: "a", Int32Value: 1, Int64Value: 1}, md)
+	assert.EqualError(t, err, "bad_request: failed rule: this.Int32Value < this.Int64Value")
+
+	err = api.ValidateRequest(api.WithAllViolations(ctx), &e2e.Annotation{Name: "b", Strings: []string{"ab"}}, md)
+	assert.Equal(t, []*api.FieldViolation{
+		{Field: "ID", Rule: api.RuleRequired, Code: api.CodeRequired, Display: "ID", Description: "ID is required"},
+		{
+			Rule:        api.RuleExpr,
+			Code:        api.CodeRule,
+			Params:      map[string]string{api.ParamRule: api.RuleExpr, api.ParamExpr: "this.Int32Value < this.Int64Value"},
+			Description: "failed rule: this.Int32Value < this.Int64Value",
+		},
+		{
+			Rule:        "strings",
+			Code:        api.CodeRule,
+			Params:      map[string]string{api.ParamRule: "strings", api.ParamExpr: "this.Strings.all(s, s.startsWith(this.Name))"},
+			Description: "Strings must start with Name",
+		},
+	}, api.FieldViolations(err))
+
+	desc := (&e2e.Annotation{}).ProtoReflect().Descriptor()
+	err = api.CompileMessageRules(desc, []*api.MessageRule{{Expr: "this.Unknown > 0"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to compile")
+	err = api.CompileMessageRules(desc, []*api.MessageRule{{Expr: "this.Name"}})
+	assert.EqualError(t, err, `expression "this.Name" must return bool, but returns string`)
+	assert.Panics(t, func() {
+		api.MustCompileMessageRules(&api.MessageDescription{Rules: []*api.MessageRule{{Expr: "this.Name"}}}, &e2e.Annotation{})
+	})
+
+	// the rule failed at runtime rejects the request, regardless of PanicPolicy
+	md = &api.MessageDescription{
+		Name:     "Annotation",
+		FullName: "e2e.Annotation",
+		Rules:    []*api.MessageRule{{Expr: "this.Int64Value / this.Int32Value > 0"}},
+	}
+	assert.NoError(t, api.ValidateRequest(ctx, &e2e.Annotation{Int32Value: 1, Int64Value: 1}, md))
+	err = api.ValidateRequest(ctx, &e2e.Annotation{Int64Value: 1}, md)
+	require.Error(t, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, err.Error(), "Annotation: failed to validate request")
+	err = api.ValidateRequest(api.WithAllViolations(ctx), &e2e.Annotation{Int64Value: 1}, md)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Empty(t, api.FieldViolations(err))
+
+	// the description created at runtime is not cached
+	md.Rules = []*api.MessageRule{{Expr: "this.Int32Value > 0", Message: "Int32Value must be positive"}}
+	err = api.ValidateRequest(ctx, &e2e.Annotation{Int64Value: 1}, md)
+	assert.EqualError(t, err, "bad_request: Int32Value must be positive")
+
+	// the invalid rule is not compiled at init
+	md = &api.MessageDescription{
+		Name:     "Annotation",
+		FullName: "e2e.Annotation",
+		Rules:    []*api.MessageRule{{Expr: "this.Unknown > 0"}},
+	}
+	err = api.ValidateRequest(ctx, &e2e.Annotation{}, md)
+	require.Error(t, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestValidateRequest_Oneofs(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Equal(t, []*api.OneofMeta{
+		{Name: "Filter", Fields: []string{"Category", "Type"}},
+	}, e2e.ListAnnotationsRequest_MessageDescription.Oneofs)
+
+	md := &api.MessageDescription{
+		Name:     "Basic",
+		FullName: "e2e.Basic",
+		Fields: []*api.FieldMeta{
+			{Name: "int", Required: true},
+			{Name: "str", Required: true, Min: 3},
+		},
+		Oneofs: []*api.OneofMeta{
+			{Name: "b", Fields: []string{"int", "str", "id"}, Required: true},
+		},
+	}
+
+	// the sibling is set, the required member is not demanded
+	assert.NoError(t, api.ValidateRequest(ctx, &e2e.Basic{B: &e2e.Basic_Int{Int: 1}}, md))
+	assert.NoError(t, api.ValidateRequest(ctx, &e2e.Basic{B: &e2e.Basic_Str{Str: "abc"}}, md))
+	assert.NoError(t, api.ValidateRequest(ctx, &e2e.Basic{B: &e2e.Basic_Id{Id: 1}}, md))
+
+	// the set member is validated
+	err := api.ValidateRequest(ctx, &e2e.Basic{B: &e2e.Basic_Str{Str: "ab"}}, md)
+	assert.EqualError(t, err, "bad_request: str: minimum length is 3")
+
+	md.Fields = nil
+	err = api.ValidateRequest(ctx, &e2e.Basic{}, md)
+	assert.EqualError(t, err, "bad_request: b: one of the fields must be set: int, str, id")
+
+	err = api.ValidateRequest(api.WithAllViolations(ctx), &e2e.Basic{}, md)
+	assert.Equal(t, []*api.FieldViolation{
+		{
+			Field:       "b",
+			Rule:        api.RuleOneof,
+			Code:        api.CodeOneofRequired,
+			Display:     "b",
+			Limit:       "int, str, id",
+			Params:      map[string]string{api.ParamFields: "int, str, id"},
+			Description: "b: one of the fields must be set: int, str, id",
+		},
+	}, api.FieldViolations(err))
+}
+
+func TestValidateRequest_Items(t *testing.T) {
+	ctx := context.Background()
+
+	basic := func() *e2e.Basic {
+		return &e2e.Basic{Name: "12345678", Map: map[string]string{"k_1": "v"}, Values: []string{"a", "b"}}
+	}
+	assert.NoError(t, basic().Validate(ctx))
+
+	msg := basic()
+	msg.Values = []string{"a", "b", "a", "a"}
+	assert.EqualError(t, msg.Validate(ctx), "bad_request: values[2]: duplicate of values[0]")
+	err := api.ValidateRequest(api.WithAllViolations(ctx), msg, e2e.Basic_MessageDescription)
+	assert.Equal(t, []*api.FieldViolation{
+		{
+			Field:       "values[2]",
+			Rule:        api.RuleUnique,
+			Code:        api.CodeUnique,
+			Display:     "values",
+			Actual:      "a",
+			Params:      map[string]string{api.ParamFirst: "values[0]"},
+			Description: "values[2]: duplicate of values[0]",
+		},
+		{
+			Field:       "values[3]",
+			Rule:        api.RuleUnique,
+			Code:        api.CodeUnique,
+			Display:     "values",
+			Actual:      "a",
+			Params:      map[string]string{api.ParamFirst: "values[0]"},
+			Description: "values[3]: duplicate of values[0]",
+		},
+	}, api.FieldViolations(err))
+
+	msg = basic()
+	msg.Values = []string{strings.Repeat("a", 65)}
+	assert.EqualError(t, msg.Validate(ctx), "bad_request: values[0]: maximum length is 64")
+
+	msg = basic()
+	msg.Map = map[string]string{"K": "v"}
+	assert.EqualError(t, msg.Validate(ctx), `bad_request: map.keys[K]: must match pattern "^[a-z0-9_]+$"`)
+
+	md := &api.MessageDescription{
+		Name:     "Annotation",
+		FullName: "e2e.Annotation",
+		Fields: []*api.FieldMeta{
+			{
+				Name:            "Types",
+				EnumDescription: e2e.AnnotationType_Enum_EnumDescription,
+				Unique:          true,
+				Items:           &api.ItemRules{In: []string{"Foo", "Bar"}},
+			},
+			{Name: "RefIDs", Unique: true, Items: &api.ItemRules{NotIn: []string{"0"}}},
+			{Name: "Map", Keys: &api.ItemRules{Min: 2, In: []string{"ab", "cd"}}},
+		},
+	}
+	assert.NoError(t, api.ValidateRequest(ctx, &e2e.Annotation{
+		Types:  []e2e.AnnotationType_Enum{e2e.AnnotationType_Foo, e2e.AnnotationType_Bar},
+		RefIDs: []uint64{1, 2},
+		Map:    map[string]string{"ab": "1", "cd": "2"},
+	}, md))
+
+	tcases := []struct {
+		name string
+		msg  *e2e.Annotation
+		exp  string
+	}{
+		{
+			name: "enum_unique",
+			msg:  &e2e.Annotation{Types: []e2e.AnnotationType_Enum{e2e.AnnotationType_Foo, e2e.AnnotationType_Foo}},
+			exp:  "bad_request: Types[1]: duplicate of Types[0]",
+		},
+		{
+			name: "enum_in",
+			msg:  &e2e.Annotation{Types: []e2e.AnnotationType_Enum{e2e.AnnotationType_Unknown}},
+			exp:  "bad_request: Types[0]: must be one of: Foo,Bar",
+		},
+		{
+			name: "uint_unique",
+			msg:  &e2e.Annotation{RefIDs: []uint64{1, 2, 1}},
+			exp:  "bad_request: RefIDs[2]: duplicate of RefIDs[0]",
+		},
+		{
+			name: "uint_not_in",
+			msg:  &e2e.Annotation{RefIDs: []uint64{1, 0}},
+			exp:  "bad_request: RefIDs[1]: must not be one of: 0",
+		},
+		{
+			name: "key_min",
+			msg:  &e2e.Annotation{Map: map[string]string{"a": "1"}},
+			exp:  "bad_request: Map.keys[a]: minimum length is 2",
+		},
+		{
+			name: "key_in",
+			msg:  &e2e.Annotation{Map: map[string]string{"ef": "1"}},
+			exp:  "bad_request: Map.keys[ef]: must be one of: ab,cd",
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, api.ValidateRequest(ctx, tc.msg, md), tc.exp)
+		})
+	}
+
+	// the field rules are applied to the list, and the item rules to the items
+	md = &api.MessageDescription{
+		Name:     "Annotation",
+		FullName: "e2e.Annotation",
+		Fields: []*api.FieldMeta{
+			{Name: "Strings", MaxCount: 2, Max: 3, Items: &api.ItemRules{Min: 5}},
+			{Name: "Types", EnumDescription: e2e.AnnotationType_Enum_EnumDescription, In: []string{"Foo"}},
+		},
+	}
+	assert.NoError(t, api.ValidateRequest(ctx, &e2e.Annotation{
+		Strings: []string{"abcdef", "abcdefgh"},
+		Types:   []e2e.AnnotationType_Enum{e2e.AnnotationType_Bar},
+	}, md))
+	err = api.ValidateRequest(api.WithAllViolations(ctx), &e2e.Annotation{
+		Strings: []string{"abc", "abcdef", "abcdef"},
+		Types:   []e2e.AnnotationType_Enum{e2e.AnnotationType_Foo, 100},
+	}, md)
+	var fields []string
+	for _, fv := range api.FieldViolations(err) {
+		fields = append(fields, fv.Field+": "+fv.Rule)
+	}
+	assert.Equal(t, []string{
+		"Strings: " + api.RuleMaxCount,
+		"Strings[0]: " + api.RuleMinLength,
+		"Types[1]: " + api.RuleEnum,
+	}, fields)
+}
+
+func TestValidateRequest_MessageCatalog(t *testing.T) {
+	catalog := api.NewMessageCatalog(map[string]string{
+		api.CodeMinLength: "{display} muss mindestens {limit} Zeichen haben, nicht {actual}",
+		api.CodeMinCount:  "{display} muss mindestens {limit} Elemente haben",
+	})
+	ctx := api.WithMessageCatalog(context.Background(), catalog)
+
+	msg := &e2e.Basic{Name: "1", Map: map[string]string{"k": "v"}, Values: []string{"a"}}
+	err := api.ValidateRequest(ctx, msg, e2e.Basic_MessageDescription)
+	assert.EqualError(t, err, "bad_request: name muss mindestens 8 Zeichen haben, nicht 1")
+
+	// the generated static validator renders with the same catalog
+	err = msg.Validate(ctx)
+	assert.EqualError(t, err, "bad_request: name muss mindestens 8 Zeichen haben, nicht 1")
+
+	err = api.ValidateRequest(api.WithAllViolations(ctx), &e2e.Basic{Name: "1"}, e2e.Basic_MessageDescription)
+	assert.Equal(t, []string{
+		"map muss mindestens 1 Elemente haben",
+		"name muss mindestens 8 Zeichen haben, nicht 1",
+		"values muss mindestens 1 Elemente haben",
+	}, violationDescriptions(api.FieldViolations(err)))
+
+	// the codes without a template have the default description
+	err = api.ValidateRequest(ctx, &e2e.Basic{
+		Name:   "12345678",
+		Map:    map[string]string{"k": "v"},
+		Values: []string{"a", "a"},
+	}, e2e.Basic_MessageDescription)
+	assert.EqualError(t, err, "bad_request: values[1]: duplicate of values[0]")
+
+	// the catalog func can fall back to the default description
+	ctx = api.WithMessageCatalog(context.Background(), api.MessageCatalogFunc(func(_ context.Context, fv *api.FieldViolation) string {
+		if fv.Rule == api.RuleMinLength {
+			return fv.Code + ":" + fv.Field
+		}
+		return ""
+	}))
+	err = api.ValidateRequest(ctx, msg, e2e.Basic_MessageDescription)
+	assert.EqualError(t, err, "bad_request: MIN_LEN:name")
+	err = api.ValidateRequest(ctx, &e2e.Basic{Name: "12345678"}, e2e.Basic_MessageDescription)
+	assert.EqualError(t, err, "bad_request: map: minimum count is 1")
+}
+
+func TestValidateRequest_FirstViolation(t *testing.T) {
+	ctx := context.Background()
+
+	// the first violation has the same data, as when all are collected
+	msg := &e2e.Basic{Name: "1", Map: map[string]string{"k": "v"}, Values: []string{"a"}}
+	exp := []*api.FieldViolation{
+		{Field: "name", Rule: api.RuleMinLength, Code: api.CodeMinLength, Display: "name", Limit: "8", Actual: "1", Description: "name: minimum length is 8"},
+	}
+	err := api.ValidateRequest(ctx, msg, e2e.Basic_MessageDescription)
+	assert.EqualError(t, err, "bad_request: name: minimum length is 8")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, exp, api.FieldViolations(err))
+	assert.Equal(t, exp, api.FieldViolations(status.Convert(err).Err()))
+
+	err = msg.Validate(ctx)
+	assert.Equal(t, exp, api.FieldViolations(err))
+
+	all := api.FieldViolations(api.ValidateRequest(api.WithAllViolations(ctx), msg, e2e.Basic_MessageDescription))
+	assert.Equal(t, exp, all)
+
+	// the catalog renders the first violation
+	ctx = api.WithMessageCatalog(ctx, api.NewMessageCatalog(map[string]string{
+		api.CodeMinLength: "{display} muss mindestens {limit} Zeichen haben",
+	}))
+	err = api.ValidateRequest(ctx, msg, e2e.Basic_MessageDescription)
+	require.Len(t, api.FieldViolations(err), 1)
+	assert.Equal(t, "name muss mindestens 8 Zeichen haben", api.FieldViolations(err)[0].Description)
+}
+
+func violationDescriptions(list []*api.FieldViolation) []string {
+	var res []string
+	for _, fv := range list {
+		res = append(res, fv.Description)
+	}
+	return res
+}
+
+func TestValidateRequest_FieldBehavior(t *testing.T) {
+	ctx := context.Background()
+
+	// google.api.field_behavior REQUIRED
+	err := api.ValidateRequest(ctx, &e2e.AnnotationRequest{}, e2e.AnnotationRequest_MessageDescription)
+	assert.EqualError(t, err, "bad_request: ID is required")
+
+	// OUTPUT_ONLY is rejected in the requests
+	valid := &e2e.Basic{Name: "12345678", Map: map[string]string{"k": "v"}, Values: []string{"a"}}
+	require.NoError(t, valid.Validate(ctx))
+	msg := proto.Clone(valid).(*e2e.Basic)
+	msg.A = "server"
+	err = api.ValidateRequest(ctx, msg, e2e.Basic_MessageDescription)
+	assert.EqualError(t, err, "bad_request: a: output only field cannot be set")
+	assert.EqualError(t, msg.Validate(ctx), "bad_request: a: output only field cannot be set")
+
+	err = api.ValidateRequest(api.WithAllViolations(ctx), msg, e2e.Basic_MessageDescription)
+	assert.Equal(t, []*api.FieldViolation{
+		{Field: "a", Rule: api.RuleOutputOnly, Code: api.CodeOutputOnly, Display: "a", Description: "a: output only field cannot be set"},
+	}, api.FieldViolations(err))
+
+	// and allowed in the responses
+	ctx = api.WithResponseValidation(ctx)
+	assert.NoError(t, api.ValidateRequest(ctx, msg, e2e.Basic_MessageDescription))
+	assert.NoError(t, msg.Validate(ctx))
+
+	md := &api.MessageDescription{
+		Name:     "Basic",
+		FullName: "e2e.Basic",
+		Fields: []*api.FieldMeta{
+			{Name: "a", OutputOnly: true, Required: true, Min: 3},
+		},
+	}
+	assert.EqualError(t, api.ValidateRequest(ctx, &e2e.Basic{}, md), "bad_request: a is required")
+	assert.EqualError(t, api.ValidateRequest(ctx, &e2e.Basic{A: "1"}, md), "bad_request: a: minimum length is 3")
+	assert.NoError(t, api.ValidateRequest(context.Background(), &e2e.Basic{}, md))
+}
+
+func TestValidateRequest_ExplicitPresence(t *testing.T) {
+	ctx := context.Background()
+
+	// unset optional is not validated as 0
+	msg := &e2e.Basic{Name: "12345678", Map: map[string]string{"k": "v"}, Values: []string{"a"}}
+	assert.NoError(t, api.ValidateRequest(ctx, msg, e2e.Basic_MessageDescription))
+	assert.NoError(t, msg.Validate(ctx))
+
+	msg.Priority = proto.Int32(0)
+	assert.EqualError(t, api.ValidateRequest(ctx, msg, e2e.Basic_MessageDescription), "bad_request: priority: minimum value is 1")
+	assert.EqualError(t, msg.Validate(ctx), "bad_request: priority: minimum value is 1")
+
+	msg.Priority = proto.Int32(11)
+	assert.EqualError(t, msg.Validate(ctx), "bad_request: priority: maximum value is 10")
+
+	md := &api.MessageDescription{
+		Name:     "Basic",
+		FullName: "e2e.Basic",
+		Fields: []*api.FieldMeta{
+			{Name: "priority", Required: true},
+			{Name: "int", Required: true},
+		},
+	}
+	assert.EqualError(t, api.ValidateRequest(ctx, &e2e.Basic{B: &e2e.Basic_Int{}}, md), "bad_request: priority is required")
+	// the oneof member is not set, even if 0 is not empty
+	assert.EqualError(t, api.ValidateRequest(ctx, &e2e.Basic{Priority: proto.Int32(0)}, md), "bad_request: int is required")
+	assert.NoError(t, api.ValidateRequest(ctx, &e2e.Basic{Priority: proto.Int32(0), B: &e2e.Basic_Int{}}, md))
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Validation rules of TimeRange reported in FieldViolation
+const (
+	RuleLtNow        = "lt_now"
+	RuleGtNow        = "gt_now"
+	RuleNotBeforeNow = "not_before_now"
+	RuleWithin       = "within"
+	RuleMinDuration  = "min_duration"
+	RuleMaxDuration  = "max_duration"
+)
+
+const (
+	timestampFullName = "google.protobuf.Timestamp"
+	durationFullName  = "google.protobuf.Duration"
+)
+
+// mustParseDuration parses TimeRange duration,
+// it panics if the duration is invalid.
+func mustParseDuration(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// secondsAndNanos returns the fields of Timestamp or Duration message
+func secondsAndNanos(msg protoreflect.Message) (int64, int64) {
+	fields := msg.Descriptor().Fields()
+	return msg.Get(fields.ByNumber(1)).Int(), msg.Get(fields.ByNumber(2)).Int()
+}
+
+// validateTimeRange checks es.api.time_range option
+func (v *validation) validateTimeRange(msg protoreflect.Message, field *FieldMeta, fieldPath string) error {
+	if field.TimeRange == nil {
+		return nil
+	}
+
+	switch msg.Descriptor().FullName() {
+	case timestampFullName:
+		sec, nanos := secondsAndNanos(msg)
+		return v.validateTimestamp(sec, nanos, field, fieldPath)
+	case durationFullName:
+		sec, nanos := secondsAndNanos(msg)
+		return v.validateDuration(sec, nanos, field, fieldPath)
+	}
+	return nil
+}
+
+// validateTimestamp checks es.api.time_range option of Timestamp
+func (v *validation) validateTimestamp(sec, nanos int64, field *FieldMeta, fieldPath string) error {
+	tr := field.TimeRange
+	ts := time.Unix(sec, nanos).UTC()
+	now := time.Now().UTC()
+	actual := ts.Format(time.RFC3339Nano)
+
+	if tr.LtNow && !ts.Before(now) {
+		if err := v.violate(violation{Field: field, Path: fieldPath, Rule: RuleLtNow, Actual: actual}, "%s: must be in the past", fieldPath); err != nil {
+			return err
+		}
+	}
+	if tr.GtNow && !ts.After(now) {
+		if err := v.violate(violation{Field: field, Path: fieldPath, Rule: RuleGtNow, Actual: actual}, "%s: must be in the future", fieldPath); err != nil {
+			return err
+		}
+	}
+	if tr.NotBeforeNow && ts.Before(now) {
+		if err := v.violate(violation{Field: field, Path: fieldPath, Rule: RuleNotBeforeNow, Actual: actual}, "%s: must not be before now", fieldPath); err != nil {
+			return err
+		}
+	}
+	if tr.Within != "" {
+		within := mustParseDuration(tr.Within)
+		if diff := ts.Sub(now).Abs(); diff > within {
+			return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleWithin, Limit: tr.Within, Actual: actual}, "%s: must be within %s from now", fieldPath, tr.Within)
+		}
+	}
+	return nil
+}
+
+// validateDuration checks es.api.time_range option of Duration
+func (v *validation) validateDuration(sec, nanos int64, field *FieldMeta, fieldPath string) error {
+	tr := field.TimeRange
+	sec, nanos = normalizeDuration(sec, nanos)
+
+	if tr.MinDuration != "" && compareDuration(sec, nanos, mustParseDuration(tr.MinDuration)) < 0 {
+		if err := v.violate(violation{Field: field, Path: fieldPath, Rule: RuleMinDuration, Limit: tr.MinDuration, Actual: durationActual(sec, nanos)}, "%s: minimum duration is %s", fieldPath, tr.MinDuration); err != nil {
+			return err
+		}
+	}
+	if tr.MaxDuration != "" && compareDuration(sec, nanos, mustParseDuration(tr.MaxDuration)) > 0 {
+		return v.violate(violation{Field: field, Path: fieldPath, Rule: RuleMaxDuration, Limit: tr.MaxDuration, Actual: durationActual(sec, nanos)}, "%s: maximum duration is %s", fieldPath, tr.MaxDuration)
+	}
+	return nil
+}
+
+// normalizeDuration returns the seconds and nanos of the Duration,
+// where nanos are in [0, 1e9) range, to compare the pairs
+func normalizeDuration(sec, nanos int64) (int64, int64) {
+	sec += nanos / int64(time.Second)
+	nanos %= int64(time.Second)
+	if nanos < 0 {
+		sec--
+		nanos += int64(time.Second)
+	}
+	return sec, nanos
+}
+
+// compareDuration compares the normalized Duration with the limit,
+// the Duration can exceed the range of time.Duration
+func compareDuration(sec, nanos int64, limit time.Duration) int {
+	lsec, lnanos := normalizeDuration(int64(limit/time.Second), int64(limit%time.Second))
+	if c := cmp.Compare(sec, lsec); c != 0 {
+		return c
+	}
+	return cmp.Compare(nanos, lnanos)
+}
+
+// durationActual returns the normalized Duration reported in FieldViolation
+func durationActual(sec, nanos int64) any {
+	if sec > math.MinInt64/int64(time.Second) && sec < math.MaxInt64/int64(time.Second) {
+		return time.Duration(sec)*time.Second + time.Duration(nanos)
+	}
+	return fmt.Sprintf("%d.%09ds", sec, nanos)
+}
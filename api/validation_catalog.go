@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"strings"
+)
+
+// MessageCatalog renders the description of FieldViolation,
+// for example in the language of the request.
+type MessageCatalog interface {
+	// Message returns the description of the violation,
+	// or empty string to use the default English description.
+	Message(ctx context.Context, fv *FieldViolation) string
+}
+
+// MessageCatalogFunc is the function adapter of MessageCatalog
+type MessageCatalogFunc func(ctx context.Context, fv *FieldViolation) string
+
+// Message returns the description of the violation
+func (f MessageCatalogFunc) Message(ctx context.Context, fv *FieldViolation) string {
+	return f(ctx, fv)
+}
+
+type templateCatalog map[string]string
+
+// NewMessageCatalog returns MessageCatalog with the message templates by Code,
+// the parameters are substituted by name in braces, for example:
+// "{display} must have at least {limit} characters".
+// The codes without a template have the default English description.
+func NewMessageCatalog(templates map[string]string) MessageCatalog {
+	return templateCatalog(templates)
+}
+
+// Message returns the rendered template of the violation Code
+func (c templateCatalog) Message(_ context.Context, fv *FieldViolation) string {
+	tpl, ok := c[fv.Code]
+	if !ok {
+		return ""
+	}
+	params := fv.AllParams()
+	oldnew := make([]string, 0, len(params)*2)
+	for k, v := range params {
+		oldnew = append(oldnew, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(oldnew...).Replace(tpl)
+}
+
+type messageCatalogKey struct{}
+
+// WithMessageCatalog returns the context, where ValidateRequest renders
+// the violation descriptions with the catalog.
+func WithMessageCatalog(ctx context.Context, catalog MessageCatalog) context.Context {
+	return context.WithValue(ctx, messageCatalogKey{}, catalog)
+}
+
+func messageCatalog(ctx context.Context) MessageCatalog {
+	catalog, _ := ctx.Value(messageCatalogKey{}).(MessageCatalog)
+	return catalog
+}
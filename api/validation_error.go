@@ -0,0 +1,318 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/effective-security/porto/xhttp/marshal"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Validation rules reported in FieldViolation
+const (
+	RuleRequired   = "required"
+	RuleRequiredOr = "required_or"
+	RuleMinLength  = "min_length"
+	RuleMaxLength  = "max_length"
+	RuleMinCount   = "min_count"
+	RuleMaxCount   = "max_count"
+	RuleMin        = "min"
+	RuleMax        = "max"
+	RulePattern    = "pattern"
+	RulePrefix     = "prefix"
+	RuleSuffix     = "suffix"
+	RuleFormat     = "format"
+	RuleEnum       = "enum"
+	RuleIn         = "in"
+	RuleNotIn      = "not_in"
+	RuleOneof      = "oneof"
+	RuleUnique     = "unique"
+	RuleOutputOnly = "output_only"
+)
+
+// Validation error codes reported in FieldViolation,
+// the codes are stable and can be used to localize the messages.
+const (
+	CodeRequired      = "REQUIRED"
+	CodeRequiredOr    = "REQUIRED_OR"
+	CodeOneofRequired = "ONEOF_REQUIRED"
+	CodeMinLength     = "MIN_LEN"
+	CodeMaxLength     = "MAX_LEN"
+	CodeMinCount      = "MIN_COUNT"
+	CodeMaxCount      = "MAX_COUNT"
+	CodeMin           = "MIN"
+	CodeMax           = "MAX"
+	CodeGreaterThan   = "GT"
+	CodeLessThan      = "LT"
+	CodePattern       = "PATTERN"
+	CodePrefix        = "PREFIX"
+	CodeSuffix        = "SUFFIX"
+	CodeFormat        = "FORMAT"
+	CodeEnum          = "ENUM"
+	CodeEnumFlags     = "ENUM_FLAGS"
+	CodeIn            = "IN"
+	CodeNotIn         = "NOT_IN"
+	CodeUnique        = "UNIQUE"
+	CodeOutputOnly    = "OUTPUT_ONLY"
+	CodeLtNow         = "LT_NOW"
+	CodeGtNow         = "GT_NOW"
+	CodeNotBeforeNow  = "NOT_BEFORE_NOW"
+	CodeWithin        = "WITHIN"
+	CodeMinDuration   = "MIN_DURATION"
+	CodeMaxDuration   = "MAX_DURATION"
+	CodeRule          = "RULE"
+)
+
+// Parameters of FieldViolation message,
+// field, display, limit and actual are provided by FieldViolation fields,
+// the codes may have specific parameters in FieldViolation.Params.
+const (
+	// ParamField is the field path
+	ParamField = "field"
+	// ParamDisplay is the field display name
+	ParamDisplay = "display"
+	// ParamLimit is the limit of the rule
+	ParamLimit = "limit"
+	// ParamActual is the actual value
+	ParamActual = "actual"
+	// ParamFields is the list of fields of REQUIRED_OR and ONEOF_REQUIRED
+	ParamFields = "fields"
+	// ParamFlags are the undefined flags of ENUM_FLAGS
+	ParamFlags = "flags"
+	// ParamFirst is the path of the first item of UNIQUE
+	ParamFirst = "first"
+	// ParamRule is the ID of the message rule of RULE
+	ParamRule = "rule"
+	// ParamExpr is the expression of the message rule of RULE
+	ParamExpr = "expr"
+)
+
+// ruleCodes maps the rules to the default error codes
+var ruleCodes = map[string]string{
+	RuleRequired:     CodeRequired,
+	RuleRequiredOr:   CodeRequiredOr,
+	RuleOneof:        CodeOneofRequired,
+	RuleMinLength:    CodeMinLength,
+	RuleMaxLength:    CodeMaxLength,
+	RuleMinCount:     CodeMinCount,
+	RuleMaxCount:     CodeMaxCount,
+	RuleMin:          CodeMin,
+	RuleMax:          CodeMax,
+	RulePattern:      CodePattern,
+	RulePrefix:       CodePrefix,
+	RuleSuffix:       CodeSuffix,
+	RuleFormat:       CodeFormat,
+	RuleEnum:         CodeEnum,
+	RuleIn:           CodeIn,
+	RuleNotIn:        CodeNotIn,
+	RuleUnique:       CodeUnique,
+	RuleOutputOnly:   CodeOutputOnly,
+	RuleLtNow:        CodeLtNow,
+	RuleGtNow:        CodeGtNow,
+	RuleNotBeforeNow: CodeNotBeforeNow,
+	RuleWithin:       CodeWithin,
+	RuleMinDuration:  CodeMinDuration,
+	RuleMaxDuration:  CodeMaxDuration,
+}
+
+// FieldViolation describes a field that failed validation
+type FieldViolation struct {
+	// Field is the path of the field, for example Basic.Values[0]
+	Field string `json:"field"`
+	// Rule is the failed validation rule, for example min_length
+	Rule string `json:"rule"`
+	// Limit is the limit of the rule, if applicable
+	Limit string `json:"limit,omitempty"`
+	// Actual is the actual value compared with the limit, if applicable
+	Actual string `json:"actual,omitempty"`
+	// Description is the human readable description of the violation,
+	// rendered by MessageCatalog, if provided
+	Description string `json:"description"`
+	// Code is the stable error code, for example MIN_LEN
+	Code string `json:"code,omitempty"`
+	// Display is the display name of the field
+	Display string `json:"display,omitempty"`
+	// Params are the code specific parameters of the message,
+	// see Param constants
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// AllParams returns the parameters of the message,
+// including field, display, limit and actual.
+func (v *FieldViolation) AllParams() map[string]string {
+	res := make(map[string]string, len(v.Params)+4)
+	for k, val := range v.Params {
+		res[k] = val
+	}
+	for k, val := range map[string]string{
+		ParamField:   v.Field,
+		ParamDisplay: v.Display,
+		ParamLimit:   v.Limit,
+		ParamActual:  v.Actual,
+	} {
+		if val != "" {
+			res[k] = val
+		}
+	}
+	return res
+}
+
+// ValidationError is returned by ValidateRequest with the first violation,
+// or with all violations, see WithAllViolations.
+// It is converted to gRPC status with errdetails.BadRequest,
+// and to JSON body with field_violations by WriteHTTPError.
+type ValidationError struct {
+	Violations []*FieldViolation
+}
+
+// Error returns the descriptions of all violations
+func (e *ValidationError) Error() string {
+	return "bad_request: " + e.message()
+}
+
+func (e *ValidationError) message() string {
+	descs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		descs[i] = v.Description
+	}
+	return strings.Join(descs, "; ")
+}
+
+// GRPCStatus returns InvalidArgument status with errdetails.BadRequest,
+// followed by errdetails.ErrorInfo with the code and the parameters
+// of each violation, in the same order.
+func (e *ValidationError) GRPCStatus() *status.Status {
+	br := &errdetails.BadRequest{}
+	details := []protoadapt.MessageV1{br}
+	for _, v := range e.Violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+			Reason:      v.Rule,
+		})
+		details = append(details, &errdetails.ErrorInfo{
+			Reason:   v.Code,
+			Metadata: v.AllParams(),
+		})
+	}
+	st := status.New(codes.InvalidArgument, e.message())
+	if ds, err := st.WithDetails(details...); err == nil {
+		return ds
+	}
+	return st
+}
+
+// validationErrorJSON is the JSON body of ValidationError,
+// compatible with httperror.Error
+type validationErrorJSON struct {
+	Code            string            `json:"code"`
+	Message         string            `json:"message"`
+	FieldViolations []*FieldViolation `json:"field_violations"`
+}
+
+// MarshalJSON returns httperror.Error compatible JSON with field_violations
+func (e *ValidationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&validationErrorJSON{
+		Code:            "bad_request",
+		Message:         e.message(),
+		FieldViolations: e.Violations,
+	})
+}
+
+// FieldViolations returns the violations from ValidationError,
+// or from errdetails.BadRequest of gRPC status.
+func FieldViolations(err error) []*FieldViolation {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return verr.Violations
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	var list []*FieldViolation
+	var infos []*errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		switch det := d.(type) {
+		case *errdetails.BadRequest:
+			for _, fv := range det.GetFieldViolations() {
+				list = append(list, &FieldViolation{
+					Field:       fv.GetField(),
+					Rule:        fv.GetReason(),
+					Description: fv.GetDescription(),
+				})
+			}
+		case *errdetails.ErrorInfo:
+			infos = append(infos, det)
+		}
+	}
+	if len(infos) == len(list) {
+		for i, info := range infos {
+			fv := list[i]
+			fv.Code = info.GetReason()
+			for k, val := range info.GetMetadata() {
+				switch k {
+				case ParamField:
+				case ParamDisplay:
+					fv.Display = val
+				case ParamLimit:
+					fv.Limit = val
+				case ParamActual:
+					fv.Actual = val
+				default:
+					if fv.Params == nil {
+						fv.Params = map[string]string{}
+					}
+					fv.Params[k] = val
+				}
+			}
+		}
+	}
+	return list
+}
+
+// WriteHTTPError writes the error response,
+// ValidationError is written with field_violations.
+func WriteHTTPError(w http.ResponseWriter, r *http.Request, err error) {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(verr)
+		return
+	}
+	marshal.WriteJSON(w, r, err)
+}
+
+type allViolationsKey struct{}
+
+// WithAllViolations returns the context, where ValidateRequest collects
+// all violations into ValidationError, instead of returning the first one.
+func WithAllViolations(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allViolationsKey{}, true)
+}
+
+func allViolations(ctx context.Context) bool {
+	all, _ := ctx.Value(allViolationsKey{}).(bool)
+	return all
+}
+
+type responseValidationKey struct{}
+
+// WithResponseValidation returns the context, where ValidateRequest validates
+// the response, and allows the OUTPUT_ONLY fields to be set.
+func WithResponseValidation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, responseValidationKey{}, true)
+}
+
+func isResponseValidation(ctx context.Context) bool {
+	res, _ := ctx.Value(responseValidationKey{}).(bool)
+	return res
+}
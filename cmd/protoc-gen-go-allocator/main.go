@@ -12,6 +12,7 @@ import (
 	"github.com/Masterminds/sprig/v3"
 	"github.com/cockroachdb/errors"
 	"github.com/effective-security/protoc-gen-go/api"
+	"github.com/effective-security/protoc-gen-go/internal/plugin"
 	"github.com/effective-security/x/slices"
 	"github.com/effective-security/xlog"
 	"google.golang.org/protobuf/compiler/protogen"
@@ -38,6 +39,8 @@ func main() {
 
 // generator
 func generator(gp *protogen.Plugin) error {
+	plugin.ConfigureFeatures(gp)
+
 	var formatter xlog.Formatter
 	if *log {
 		formatter = xlog.NewStringFormatter(os.Stderr).
@@ -223,6 +226,7 @@ import (
 
 	"github.com/effective-security/porto/xhttp/httperror"
 	"github.com/effective-security/porto/xhttp/marshal"
+	"github.com/effective-security/protoc-gen-go/api"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -241,6 +245,22 @@ type MethodInfo struct {
 	RefreshInterval uint32
 }
 
+// GetAllowedRoles returns AllowedRoles
+func (m *MethodInfo) GetAllowedRoles() []string {
+	if m == nil {
+		return nil
+	}
+	return m.AllowedRoles
+}
+
+// GetScopes returns Scopes
+func (m *MethodInfo) GetScopes() []string {
+	if m == nil {
+		return nil
+	}
+	return m.Scopes
+}
+
 // UnmarshalRequest unmarshals JSON body of HTTP request to protobuf request
 func UnmarshalRequest(w http.ResponseWriter, r *http.Request) (any, *MethodInfo, error) {
 	info := methods[r.URL.Path]
@@ -264,6 +284,15 @@ func GetMethodInfo(method string) *MethodInfo {
 	return methods[method]
 }
 
+// GetMethodPolicy returns api.MethodPolicy for api.ServerPolicy,
+// or nil if the method is not found
+func GetMethodPolicy(method string) api.MethodPolicy {
+	if info := methods[method]; info != nil {
+		return info
+	}
+	return nil
+}
+
 // GetMethodsInfo returns map of methods
 func GetMethodsInfo() map[string]*MethodInfo {
 	return methods
@@ -8,6 +8,7 @@ import (
 
 	"github.com/cockroachdb/errors"
 	"github.com/effective-security/protoc-gen-go/internal/enumgen"
+	"github.com/effective-security/protoc-gen-go/internal/plugin"
 	"github.com/effective-security/xlog"
 	"google.golang.org/protobuf/compiler/protogen"
 )
@@ -22,8 +23,17 @@ var (
 	importpath   = flag.String("import", "", "go import path")
 	pkgName      = flag.String("package", "", "go package name")
 	modelPkgName = flag.String("model-pkg", "modelpb", "go package name for model types")
+	staticValid  = flag.Bool("static-validate", false, "generate static Validate methods, instead of reflection")
+	modelTags    []string
 )
 
+func init() {
+	flag.Func("model-tag", "additional struct tag of the models, can be repeated: yaml|db|bson|validate", func(tag string) error {
+		modelTags = append(modelTags, tag)
+		return nil
+	})
+}
+
 func main() {
 	flag.Parse()
 	defer logger.Flush()
@@ -31,6 +41,8 @@ func main() {
 	protogen.Options{
 		ParamFunc: flag.CommandLine.Set,
 	}.Run(func(gp *protogen.Plugin) error {
+		plugin.ConfigureFeatures(gp)
+
 		var formatter xlog.Formatter
 		if *log {
 			formatter = xlog.NewStringFormatter(os.Stderr).
@@ -42,8 +54,9 @@ func main() {
 		xlog.SetFormatter(formatter)
 
 		dopts := enumgen.Opts{
-			Package:      *pkgName,
-			ModelPackage: *modelPkgName,
+			Package:        *pkgName,
+			ModelPackage:   *modelPkgName,
+			StaticValidate: *staticValid,
 		}
 
 		if dopts.Package == "" {
@@ -52,9 +65,17 @@ func main() {
 		if dopts.ModelPackage == "" {
 			return errors.Errorf("model package is required")
 		}
+		tags, err := enumgen.ParseModelTags(modelTags)
+		if err != nil {
+			return err
+		}
+		dopts.ModelTags = tags
 
 		allEnums := enumgen.GetEnumsDescriptions(gp, dopts)
-		msgs := enumgen.GetMessagesDescriptions(gp, dopts)
+		msgs, err := enumgen.GetMessagesDescriptions(gp, dopts)
+		if err != nil {
+			return err
+		}
 
 		if len(msgs) > 0 {
 			fn := fmt.Sprintf("%s.pb.go", *outMsgs)
@@ -8,6 +8,7 @@ import (
 
 	"github.com/cockroachdb/errors"
 	"github.com/effective-security/protoc-gen-go/internal/httpgen"
+	"github.com/effective-security/protoc-gen-go/internal/plugin"
 	"github.com/effective-security/xlog"
 	"google.golang.org/protobuf/compiler/protogen"
 )
@@ -18,6 +19,7 @@ var (
 	log       = flag.Bool("logs", true, "output logs")
 	pkgName   = flag.String("pkg", "httppb", "go package name")
 	pbPkgName = flag.String("pbpkg", "pb", "go package name for main protobuf types")
+	validate  = flag.Bool("validate", false, "validate requests before calling the service")
 )
 
 func main() {
@@ -27,6 +29,8 @@ func main() {
 	protogen.Options{
 		ParamFunc: flag.CommandLine.Set,
 	}.Run(func(gp *protogen.Plugin) error {
+		plugin.ConfigureFeatures(gp)
+
 		var formatter xlog.Formatter
 		if *log {
 			formatter = xlog.NewStringFormatter(os.Stderr).
@@ -49,6 +53,7 @@ func main() {
 		opts := httpgen.Options{
 			Package:   pkg,
 			PbPackage: *pbPkgName,
+			Validate:  *validate,
 		}
 
 		for _, name := range gp.Request.FileToGenerate {
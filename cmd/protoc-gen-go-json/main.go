@@ -7,6 +7,7 @@ import (
 	"path"
 
 	"github.com/effective-security/protoc-gen-go/internal/jsongen"
+	"github.com/effective-security/protoc-gen-go/internal/plugin"
 	"github.com/effective-security/xlog"
 	"google.golang.org/protobuf/compiler/protogen"
 )
@@ -30,6 +31,8 @@ func main() {
 	protogen.Options{
 		ParamFunc: flag.CommandLine.Set,
 	}.Run(func(gp *protogen.Plugin) error {
+		plugin.ConfigureFeatures(gp)
+
 		var formatter xlog.Formatter
 		if *log {
 			formatter = xlog.NewStringFormatter(os.Stderr).
@@ -0,0 +1,142 @@
+package e2e_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/effective-security/protoc-gen-go/e2e"
+	"github.com/effective-security/protoc-gen-go/e2e/modelpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestModelConverters(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	priority := int32(5)
+
+	pb := &e2e.Annotation{
+		ID:   "123456789",
+		Name: "annotation",
+		Type: e2e.AnnotationType_Bar,
+		Map:  map[string]string{"k": "v"},
+		Metadata: []*e2e.KVPair{
+			{Key: "k1", Value: "v1"},
+			{Key: "k2"},
+		},
+		Basic: &e2e.Basic{
+			A:             "a",
+			B:             &e2e.Basic_Str{Str: "str"},
+			Map:           map[string]string{"key": "value"},
+			Created:       timestamppb.New(created),
+			Statuses:      e2e.JobStatus_Running,
+			ResourceTypes: e2e.ResourceType_S3Bucket | e2e.ResourceType_EC2Instance,
+			Name:          "basic name",
+			Values:        []string{"one", "two"},
+			Priority:      &priority,
+		},
+		FloatValue: 1.5,
+		BytesValue: []byte("bytes"),
+		Strings:    []string{"s1", "s2"},
+		Types:      []e2e.AnnotationType_Enum{e2e.AnnotationType_Unknown, e2e.AnnotationType_Foo},
+		RefIDs:     []uint64{1, 2},
+		Hashes:     []int64{-1},
+		Limits:     []uint32{3},
+		Counts:     []int32{-3},
+	}
+
+	m, err := modelpb.AnnotationToModel(pb)
+	require.NoError(t, err)
+	assert.Equal(t, "123456789", m.ID)
+	assert.Equal(t, e2e.AnnotationType_Bar, m.Type)
+	require.Len(t, m.Metadata, 2)
+	assert.Equal(t, "k1", m.Metadata[0].Key)
+	require.NotNil(t, m.Basic)
+	require.NotNil(t, m.Basic.Str)
+	assert.Equal(t, "str", *m.Basic.Str)
+	assert.Nil(t, m.Basic.Int)
+	assert.Equal(t, created, m.Basic.Created)
+	assert.Equal(t, e2e.JobStatus_Running, m.Basic.Statuses)
+	require.NotNil(t, m.Basic.Priority)
+	assert.Equal(t, priority, *m.Basic.Priority)
+
+	// the model does not share the values with the message
+	m.Strings[0] = "changed"
+	m.Map["k"] = "changed"
+	assert.Equal(t, "s1", pb.Strings[0])
+	assert.Equal(t, "v", pb.Map["k"])
+	m.Strings[0] = "s1"
+	m.Map["k"] = "v"
+
+	res, err := modelpb.AnnotationFromModel(m)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(pb, res), "%v", res)
+
+	// oneof and optional are not set
+	pb.Basic.B = &e2e.Basic_Id{Id: 42}
+	pb.Basic.Priority = nil
+	pb.Basic.Created = nil
+	m, err = modelpb.AnnotationToModel(pb)
+	require.NoError(t, err)
+	assert.Nil(t, m.Basic.Priority)
+	assert.True(t, m.Basic.Created.IsZero())
+	res, err = modelpb.AnnotationFromModel(m)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(pb, res), "%v", res)
+
+	// the optional, oneof and Duration zero values are preserved
+	zero := int32(0)
+	for _, basic := range []*e2e.Basic{
+		{Priority: &zero},
+		{B: &e2e.Basic_Int{Int: 0}},
+		{B: &e2e.Basic_Str{}},
+		{Timeout: &durationpb.Duration{}},
+		{Timeout: durationpb.New(-time.Minute)},
+	} {
+		bm, err := modelpb.BasicToModel(basic)
+		require.NoError(t, err)
+		rb, err := modelpb.BasicFromModel(bm)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(basic, rb), "%v", rb)
+	}
+
+	// the Duration out of the time.Duration range is not saturated
+	_, err = modelpb.BasicToModel(&e2e.Basic{Timeout: &durationpb.Duration{Seconds: 1 << 35}})
+	assert.Error(t, err)
+
+	m, err = modelpb.AnnotationToModel(nil)
+	require.NoError(t, err)
+	assert.Nil(t, m)
+	res, err = modelpb.AnnotationFromModel(nil)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	res, err = modelpb.AnnotationFromModel(&modelpb.Annotation{Metadata: []*modelpb.KVPair{nil, {Key: "k"}}})
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(&e2e.Annotation{Metadata: []*e2e.KVPair{{Key: "k"}}}, res), "%v", res)
+}
+
+func TestModelTags(t *testing.T) {
+	typ := reflect.TypeFor[modelpb.Annotation]()
+
+	id, ok := typ.FieldByName("ID")
+	require.True(t, ok)
+	assert.Equal(t, "ID,omitempty", id.Tag.Get("json"))
+	assert.Equal(t, "ID,omitempty", id.Tag.Get("yaml"))
+	assert.Equal(t, "id", id.Tag.Get("db"))
+	// es.api.model_tags overrides the generated tag
+	assert.Equal(t, "_id", id.Tag.Get("bson"))
+	assert.Equal(t, "required,min=9,max=19", id.Tag.Get("validate"))
+
+	f, ok := typ.FieldByName("Uint64Value")
+	require.True(t, ok)
+	assert.Equal(t, "uint64_value", f.Tag.Get("db"))
+
+	f, ok = typ.FieldByName("Types")
+	require.True(t, ok)
+	_, ok = f.Tag.Lookup("validate")
+	assert.False(t, ok)
+}
@@ -0,0 +1,314 @@
+package enumgen
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// modelConversion is the conversion of a value between the protobuf
+// message and the model, the pre statements declare the variables
+// used by expr.
+type modelConversion struct {
+	pre  []string
+	expr string
+	// err is true, if expr returns the value and error
+	err bool
+}
+
+// wrapperConstructors are the wrapperspb constructors of the wrappers types
+var wrapperConstructors = map[string]string{
+	"google.protobuf.DoubleValue": "Double",
+	"google.protobuf.FloatValue":  "Float",
+	"google.protobuf.Int64Value":  "Int64",
+	"google.protobuf.UInt64Value": "UInt64",
+	"google.protobuf.Int32Value":  "Int32",
+	"google.protobuf.UInt32Value": "UInt32",
+	"google.protobuf.BoolValue":   "Bool",
+	"google.protobuf.StringValue": "String",
+	"google.protobuf.BytesValue":  "Bytes",
+}
+
+// hasModelConverters returns true, if the converters are generated
+// for the model of the message.
+func hasModelConverters(fullName string) bool {
+	md := messageDescriptions[fullName]
+	return md != nil && md.ProtogenMessage != nil
+}
+
+// goIdentName returns the name of the Go identifier, qualified with the package name,
+// if the package is not the model package.
+func goIdentName(ident protogen.GoIdent, opts Opts) string {
+	pkg := path.Base(string(ident.GoImportPath))
+	if pkg == opts.ModelPackage {
+		return ident.GoName
+	}
+	return pkg + "." + ident.GoName
+}
+
+// modelConverters returns ToModel and FromModel functions of the message,
+// the functions copy the fields without reflection,
+// so the model can be converted back to the same protobuf message.
+// The converters are generated only for the messages with protogen definition.
+func modelConverters(md *MessageDescription, opts Opts) string {
+	if !hasModelConverters(md.FullName) {
+		return ""
+	}
+	msg := messageDescriptions[md.FullName].ProtogenMessage
+	name := msg.GoIdent.GoName
+	pbName := goIdentName(msg.GoIdent, opts)
+
+	to := &strings.Builder{}
+	from := &strings.Builder{}
+	for _, field := range md.Fields {
+		if !canConvertField(field) {
+			fmt.Fprintf(to, "// %s is not converted\n", field.GoName)
+			fmt.Fprintf(from, "// %s is not converted\n", field.GoName)
+			continue
+		}
+		fieldToModel(to, field, opts)
+		fieldFromModel(from, field, opts)
+	}
+
+	res := &strings.Builder{}
+	fmt.Fprintf(res, "\n// %sToModel returns the model of the %s message.\n", name, md.FullName)
+	fmt.Fprintf(res, "func %sToModel(pb *%s) (*%s, error) {\n", name, pbName, name)
+	res.WriteString("if pb == nil {\nreturn nil, nil\n}\n")
+	fmt.Fprintf(res, "m := &%s{}\n", name)
+	res.WriteString(to.String())
+	res.WriteString("return m, nil\n}\n")
+
+	fmt.Fprintf(res, "\n// %sFromModel returns the %s message of the model.\n", name, md.FullName)
+	fmt.Fprintf(res, "func %sFromModel(m *%s) (*%s, error) {\n", name, name, pbName)
+	res.WriteString("if m == nil {\nreturn nil, nil\n}\n")
+	fmt.Fprintf(res, "pb := &%s{}\n", pbName)
+	res.WriteString(from.String())
+	res.WriteString("return pb, nil\n}\n")
+	return res.String()
+}
+
+// canConvertField returns false, if the model of the field
+// has no definition, or the protobuf type of the field is unknown.
+func canConvertField(field *FieldMeta) bool {
+	switch field.Type {
+	case "struct", "object", "[]struct":
+	case "map":
+		def := modelFields(field)
+		if len(def) != 2 {
+			return false
+		}
+		if def[1].Type != "struct" {
+			return true
+		}
+		field = def[1]
+	default:
+		return true
+	}
+	if field.ProtogenField == nil || field.ProtogenField.Message == nil {
+		return false
+	}
+	if _, ok := wellKnownModelType(field.StructName); ok {
+		return true
+	}
+	return modelType(field) == "json.RawMessage" || hasModelConverters(field.StructName)
+}
+
+// fieldToModel writes the conversion of the field to the model.
+func fieldToModel(w *strings.Builder, field *FieldMeta, opts Opts) {
+	dst := "m." + field.GoName
+	getter := fmt.Sprintf("pb.Get%s()", field.GoName)
+	pf := field.ProtogenField
+	oneof := pf != nil && isOneofMember(pf)
+	// set is the condition of the set oneof member x
+	set := func() string {
+		return fmt.Sprintf("x, ok := pb.%s.(*%s); ok", pf.Oneof.GoName, goIdentName(pf.GoIdent, opts))
+	}
+
+	switch field.Type {
+	case "struct", "object":
+		fmt.Fprintf(w, "if x := %s; x != nil {\n", getter)
+		writeConversion(w, messageToModel(field), dst)
+		w.WriteString("}\n")
+	case "[]struct":
+		fmt.Fprintf(w, "if list := %s; len(list) > 0 {\n", getter)
+		fmt.Fprintf(w, "%s = make([]%s, len(list))\n", dst, modelType(field))
+		w.WriteString("for i, x := range list {\n")
+		writeConversion(w, messageToModel(field), dst+"[i]")
+		w.WriteString("}\n}\n")
+	case "map":
+		def := modelFields(field)
+		if def[1].Type != "struct" {
+			fmt.Fprintf(w, "%s = maps.Clone(%s)\n", dst, getter)
+			return
+		}
+		fmt.Fprintf(w, "if mp := %s; len(mp) > 0 {\n", getter)
+		fmt.Fprintf(w, "%s = make(map[%s]%s, len(mp))\n", dst, def[0].Type, modelType(def[1]))
+		w.WriteString("for k, x := range mp {\n")
+		writeConversion(w, messageToModel(def[1]), dst+"[k]")
+		w.WriteString("}\n}\n")
+	case "[]byte":
+		if oneof {
+			// the set empty bytes are not nil in the model
+			fmt.Fprintf(w, "if %s {\n%s = append([]byte{}, x.%s...)\n}\n", set(), dst, field.GoName)
+			return
+		}
+		fmt.Fprintf(w, "%s = bytes.Clone(%s)\n", dst, getter)
+	default:
+		switch {
+		case field.Optional && oneof:
+			fmt.Fprintf(w, "if %s {\nv := x.%s\n%s = &v\n}\n", set(), field.GoName, dst)
+		case field.Optional:
+			fmt.Fprintf(w, "if pb.%s != nil {\nv := *pb.%s\n%s = &v\n}\n", field.GoName, field.GoName, dst)
+		case strings.HasPrefix(field.Type, "[]"):
+			fmt.Fprintf(w, "%s = slices.Clone(%s)\n", dst, getter)
+		default:
+			fmt.Fprintf(w, "%s = %s\n", dst, getter)
+		}
+	}
+}
+
+// fieldFromModel writes the conversion of the field from the model.
+func fieldFromModel(w *strings.Builder, field *FieldMeta, opts Opts) {
+	src := "m." + field.GoName
+	dst := "pb." + field.GoName
+	assign := func(v string) string {
+		return fmt.Sprintf("%s = %s\n", dst, v)
+	}
+	pf := field.ProtogenField
+	oneof := pf != nil && isOneofMember(pf)
+	if oneof {
+		assign = func(v string) string {
+			return fmt.Sprintf("pb.%s = &%s{%s: %s}\n", pf.Oneof.GoName, goIdentName(pf.GoIdent, opts), field.GoName, v)
+		}
+	}
+
+	switch field.Type {
+	case "struct", "object":
+		cond, conv := messageFromModel(field, src, opts)
+		fmt.Fprintf(w, "if %s {\n", cond)
+		w.WriteString(assign(writeConversion(w, conv, "")))
+		w.WriteString("}\n")
+	case "[]struct":
+		_, conv := messageFromModel(field, "v", opts)
+		fmt.Fprintf(w, "if len(%s) > 0 {\n", src)
+		fmt.Fprintf(w, "%s = make([]*%s, 0, len(%s))\n", dst, goIdentName(pf.Message.GoIdent, opts), src)
+		fmt.Fprintf(w, "for _, v := range %s {\n", src)
+		if strings.HasPrefix(modelType(field), "*") {
+			// nil is not a valid list element
+			w.WriteString("if v == nil {\ncontinue\n}\n")
+		}
+		fmt.Fprintf(w, "%s = append(%s, %s)\n", dst, dst, writeConversion(w, conv, ""))
+		w.WriteString("}\n}\n")
+	case "map":
+		def := modelFields(field)
+		if def[1].Type != "struct" {
+			fmt.Fprintf(w, "%s = maps.Clone(%s)\n", dst, src)
+			return
+		}
+		_, conv := messageFromModel(def[1], "v", opts)
+		fmt.Fprintf(w, "if len(%s) > 0 {\n", src)
+		fmt.Fprintf(w, "%s = make(map[%s]*%s, len(%s))\n", dst, def[0].Type, goIdentName(def[1].ProtogenField.Message.GoIdent, opts), src)
+		fmt.Fprintf(w, "for k, v := range %s {\n", src)
+		if strings.HasPrefix(modelType(def[1]), "*") {
+			w.WriteString("if v == nil {\ncontinue\n}\n")
+		}
+		fmt.Fprintf(w, "%s[k] = %s\n", dst, writeConversion(w, conv, ""))
+		w.WriteString("}\n}\n")
+	default:
+		switch {
+		case field.Optional && oneof:
+			fmt.Fprintf(w, "if %s != nil {\n%s}\n", src, assign("*"+src))
+		case field.Optional:
+			fmt.Fprintf(w, "if %s != nil {\nv := *%s\n%s = &v\n}\n", src, src, dst)
+		case field.Type == "[]byte" && oneof:
+			fmt.Fprintf(w, "if %s != nil {\n%s}\n", src, assign(fmt.Sprintf("bytes.Clone(%s)", src)))
+		case field.Type == "[]byte":
+			w.WriteString(assign(fmt.Sprintf("bytes.Clone(%s)", src)))
+		case strings.HasPrefix(field.Type, "[]"):
+			w.WriteString(assign(fmt.Sprintf("slices.Clone(%s)", src)))
+		default:
+			w.WriteString(assign(src))
+		}
+	}
+}
+
+// writeConversion writes the conversion statements, the result is assigned
+// to dst, or returned as the expression, if dst is empty.
+func writeConversion(w *strings.Builder, conv *modelConversion, dst string) string {
+	for _, pre := range conv.pre {
+		w.WriteString(pre)
+		w.WriteString("\n")
+	}
+	switch {
+	case conv.err:
+		fmt.Fprintf(w, "res, err := %s\nif err != nil {\nreturn nil, err\n}\n", conv.expr)
+		if dst == "" {
+			return "res"
+		}
+		fmt.Fprintf(w, "%s = res\n", dst)
+	case dst == "":
+		return conv.expr
+	default:
+		fmt.Fprintf(w, "%s = %s\n", dst, conv.expr)
+	}
+	return dst
+}
+
+// messageToModel returns the conversion of the non-nil message x to the model type.
+func messageToModel(field *FieldMeta) *modelConversion {
+	switch field.StructName {
+	case "google.protobuf.Timestamp":
+		return &modelConversion{expr: "x.AsTime()"}
+	case "google.protobuf.Duration":
+		return &modelConversion{expr: "api.DurationModel(x)", err: true}
+	case "google.protobuf.Struct":
+		return &modelConversion{expr: "x.AsMap()"}
+	case "google.protobuf.Value":
+		return &modelConversion{expr: "x.AsInterface()"}
+	case "google.protobuf.ListValue":
+		return &modelConversion{expr: "x.AsSlice()"}
+	case "google.protobuf.BytesValue":
+		return &modelConversion{expr: "append([]byte{}, x.GetValue()...)"}
+	}
+	if _, ok := wrapperConstructors[field.StructName]; ok {
+		return &modelConversion{pre: []string{"v := x.GetValue()"}, expr: "&v"}
+	}
+	if modelType(field) == "json.RawMessage" {
+		return &modelConversion{expr: "protojson.Marshal(x)", err: true}
+	}
+	return &modelConversion{expr: fmt.Sprintf("%sToModel(x)", modelName(field.StructName)), err: true}
+}
+
+// messageFromModel returns the condition of the set src value,
+// and the conversion of src to the message.
+func messageFromModel(field *FieldMeta, src string, opts Opts) (string, *modelConversion) {
+	switch field.StructName {
+	case "google.protobuf.Timestamp":
+		return fmt.Sprintf("!%s.IsZero()", src), &modelConversion{expr: fmt.Sprintf("timestamppb.New(%s)", src)}
+	case "google.protobuf.Duration":
+		return fmt.Sprintf("%s != nil", src), &modelConversion{expr: fmt.Sprintf("durationpb.New(*%s)", src)}
+	case "google.protobuf.Struct":
+		return fmt.Sprintf("%s != nil", src), &modelConversion{expr: fmt.Sprintf("structpb.NewStruct(%s)", src), err: true}
+	case "google.protobuf.Value":
+		return fmt.Sprintf("%s != nil", src), &modelConversion{expr: fmt.Sprintf("structpb.NewValue(%s)", src), err: true}
+	case "google.protobuf.ListValue":
+		return fmt.Sprintf("%s != nil", src), &modelConversion{expr: fmt.Sprintf("structpb.NewList(%s)", src), err: true}
+	case "google.protobuf.BytesValue":
+		return fmt.Sprintf("%s != nil", src), &modelConversion{expr: fmt.Sprintf("wrapperspb.Bytes(bytes.Clone(%s))", src)}
+	}
+	if ctor, ok := wrapperConstructors[field.StructName]; ok {
+		return fmt.Sprintf("%s != nil", src), &modelConversion{expr: fmt.Sprintf("wrapperspb.%s(*%s)", ctor, src)}
+	}
+	if modelType(field) == "json.RawMessage" {
+		return fmt.Sprintf("len(%s) > 0", src), &modelConversion{
+			pre: []string{
+				fmt.Sprintf("msg := &%s{}", goIdentName(field.ProtogenField.Message.GoIdent, opts)),
+				fmt.Sprintf("if err := protojson.Unmarshal(%s, msg); err != nil {\nreturn nil, err\n}", src),
+			},
+			expr: "msg",
+		}
+	}
+	return fmt.Sprintf("%s != nil", src), &modelConversion{expr: fmt.Sprintf("%sFromModel(%s)", modelName(field.StructName), src), err: true}
+}
@@ -0,0 +1,1009 @@
+package enumgen
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/effective-security/protoc-gen-go/api"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// staticValidators returns Validate methods of the input messages of the package,
+// with the direct checks of MessageDescription fields,
+// the checks must produce the same results as api.ValidateRequest.
+// The nested messages with constraints get validate<Name>Fields functions,
+// that are called instead of traversing the nested message with protoreflect.
+func staticValidators(msgs []*MessageDescription, pkg string) string {
+	s := &staticSet{
+		pkg:      pkg,
+		byName:   map[string]*MessageDescription{},
+		checked:  map[string]bool{},
+		nested:   map[string]bool{},
+		patterns: map[string]string{},
+	}
+	for _, md := range msgs {
+		if md.ProtogenMessage != nil {
+			s.byName[md.FullName] = md
+		}
+	}
+	s.resolveChecks(msgs)
+
+	// the nested messages, reachable from the inputs
+	var queue []*MessageDescription
+	for _, md := range msgs {
+		if md.IsInput && md.Package == pkg && md.ProtogenMessage != nil {
+			queue = append(queue, md)
+		}
+	}
+	inputs := queue
+	for len(queue) > 0 {
+		md := queue[0]
+		queue = queue[1:]
+		for _, target := range s.targets(md) {
+			if !s.nested[target.FullName] {
+				s.nested[target.FullName] = true
+				queue = append(queue, target)
+			}
+		}
+	}
+
+	body := &strings.Builder{}
+	for _, md := range inputs {
+		body.WriteString(s.validator(md))
+	}
+	for _, md := range msgs {
+		if s.nested[md.FullName] {
+			body.WriteString(s.fieldsValidator(md))
+		}
+	}
+
+	res := &strings.Builder{}
+	code := body.String()
+	names := make([]string, 0, len(s.patterns))
+	for name := range s.patterns {
+		// only the patterns of the generated checks
+		if strings.Contains(code, name+".") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		res.WriteString("var (\n")
+		for _, name := range names {
+			fmt.Fprintf(res, "%s = regexp.MustCompile(%q)\n", name, s.patterns[name])
+		}
+		res.WriteString(")\n\n")
+	}
+	res.WriteString(code)
+	return res.String()
+}
+
+type staticSet struct {
+	pkg    string
+	byName map[string]*MessageDescription
+	// checked are the messages, which fields have constraints
+	checked map[string]bool
+	// nested are the messages with validate<Name>Fields functions
+	nested map[string]bool
+	// patterns are the compiled es.api.pattern options
+	patterns map[string]string
+}
+
+// resolveChecks finds the messages with constraints,
+// including the constraints of the nested messages
+func (s *staticSet) resolveChecks(msgs []*MessageDescription) {
+	for changed := true; changed; {
+		changed = false
+		for _, md := range msgs {
+			if md.ProtogenMessage == nil || s.checked[md.FullName] {
+				continue
+			}
+			if s.newGen(md, false).fields() != "" {
+				s.checked[md.FullName] = true
+				changed = true
+			}
+		}
+	}
+}
+
+// target returns the description of the nested message,
+// which fields are validated by ValidateRequest, or nil.
+func (s *staticSet) target(fm *FieldMeta, f *protogen.Field) *MessageDescription {
+	// the nested fields of maps are not populated at runtime
+	if f.Desc.IsMap() || f.Message == nil || fm.StructName == "" {
+		return nil
+	}
+	md := s.byName[fm.StructName]
+	if md == nil || !s.checked[md.FullName] {
+		return nil
+	}
+	return md
+}
+
+// targets returns the nested messages of the package,
+// validated by validate<Name>Fields functions.
+func (s *staticSet) targets(md *MessageDescription) []*MessageDescription {
+	g := s.newGen(md, false)
+	var res []*MessageDescription
+	for _, fm := range md.Fields {
+		f := g.protoField(fm.Name)
+		if f == nil {
+			continue
+		}
+		if t := s.target(fm, f); t != nil && t.Package == s.pkg {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+func (s *staticSet) newGen(md *MessageDescription, prefixed bool) *staticGen {
+	return &staticGen{
+		s:        s,
+		md:       md,
+		prefixed: prefixed,
+		hasVars:  map[string]string{},
+	}
+}
+
+// validator returns Validate method of the input message
+func (s *staticSet) validator(md *MessageDescription) string {
+	res := &strings.Builder{}
+	fmt.Fprintf(res, "func (m *%s) Validate(ctx context.Context) error {\n", md.Name)
+	fmt.Fprintf(res, "return api.ValidateStatic(ctx, m, %s_MessageDescription, validate%s)\n}\n\n", md.Name, md.Name)
+
+	g := s.newGen(md, false)
+	body := &strings.Builder{}
+	if s.nested[md.FullName] {
+		fmt.Fprintf(body, "if err := validate%sFields(v, m, \"\"); err != nil {\nreturn err\n}\n", md.Name)
+	} else {
+		body.WriteString(g.fields())
+	}
+	for i, oneof := range md.Oneofs {
+		if !oneof.Required {
+			continue
+		}
+		o := g.oneof(oneof.Name)
+		if o == nil {
+			continue
+		}
+		fmt.Fprintf(body, "if m.%s == nil {\n", o.GoName)
+		fmt.Fprintf(body, "if err := v.Oneof(%s_MessageDescription.Oneofs[%d]); err != nil {\nreturn err\n}\n}\n", md.Name, i)
+	}
+
+	fmt.Fprintf(res, "func validate%s(v *api.Validation, m *%s) error {\n", md.Name, md.Name)
+	g.header(res, body.String())
+	res.WriteString("return nil\n}\n\n")
+	return res.String()
+}
+
+// fieldsValidator returns the function, which validates the fields of the nested message,
+// the field paths start with the prefix.
+func (s *staticSet) fieldsValidator(md *MessageDescription) string {
+	g := s.newGen(md, true)
+	body := g.fields()
+
+	res := &strings.Builder{}
+	fmt.Fprintf(res, "func validate%sFields(v *api.Validation, m *%s, prefix string) error {\n", md.Name, md.Name)
+	// the nil items of repeated fields are validated as empty messages
+	fmt.Fprintf(res, "if m == nil {\nm = &%s{}\n}\n", md.Name)
+	g.header(res, body)
+	res.WriteString("return nil\n}\n\n")
+	return res.String()
+}
+
+type staticGen struct {
+	s  *staticSet
+	md *MessageDescription
+	// prefixed is true, if the field paths start with prefix argument
+	prefixed bool
+	// hasVars are the variables of the set oneof members,
+	// declared at the beginning of the function
+	hasVars map[string]string
+	// nonEmpty is the getter of the field, which checks are written
+	// in the branch of the present value, so it is not checked to be empty
+	nonEmpty string
+}
+
+// header writes the variables of the function, and the body
+func (g *staticGen) header(w *strings.Builder, body string) {
+	if strings.Contains(body, "fields[") {
+		fmt.Fprintf(w, "fields := %s_MessageDescription.Fields\n", g.md.Name)
+	}
+	names := make([]string, 0, len(g.hasVars))
+	for name := range g.hasVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "_, %s := %s\n", name, g.hasVars[name])
+	}
+	w.WriteString(body)
+}
+
+// fields returns the checks of the message fields
+func (g *staticGen) fields() string {
+	w := &strings.Builder{}
+	for i, fm := range g.md.Fields {
+		g.field(w, i, fm)
+	}
+	return w.String()
+}
+
+// path returns the expression of the field path
+func (g *staticGen) path(name string) string {
+	if g.prefixed {
+		return "prefix+" + strconv.Quote(name)
+	}
+	return strconv.Quote(name)
+}
+
+func (g *staticGen) oneof(name string) *protogen.Oneof {
+	for _, o := range g.md.ProtogenMessage.Oneofs {
+		if string(o.Desc.Name()) == name {
+			return o
+		}
+	}
+	return nil
+}
+
+func (g *staticGen) protoField(name string) *protogen.Field {
+	for _, f := range g.md.ProtogenMessage.Fields {
+		if string(f.Desc.Name()) == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// isOneofMember returns true for the fields of the real oneof,
+// proto3 optional fields are wrapped in synthetic oneofs
+func isOneofMember(f *protogen.Field) bool {
+	return f.Oneof != nil && !f.Oneof.Desc.IsSynthetic()
+}
+
+// hasVar returns the variable, which is true if the oneof member is set
+func (g *staticGen) hasVar(f *protogen.Field) string {
+	name := "has" + f.GoName
+	g.hasVars[name] = fmt.Sprintf("m.%s.(*%s)", f.Oneof.GoName, f.GoIdent.GoName)
+	return name
+}
+
+// presence returns the expression of the field presence,
+// the same as hasFieldValue of the validator.
+func (g *staticGen) presence(f *protogen.Field) string {
+	getter := "m.Get" + f.GoName + "()"
+	if f.Desc.IsList() || f.Desc.IsMap() {
+		return "len(" + getter + ") > 0"
+	}
+
+	switch {
+	case isOneofMember(f):
+		return g.hasVar(f)
+	case f.Desc.HasPresence():
+		return "m." + f.GoName + " != nil"
+	}
+
+	kind := f.Desc.Kind()
+	switch kind {
+	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.MessageKind, protoreflect.GroupKind:
+	default:
+		// scalar values are not considered empty
+		return "true"
+	}
+
+	switch {
+	case kind == protoreflect.StringKind:
+		return getter + ` != ""`
+	case kind == protoreflect.BytesKind:
+		return "len(" + getter + ") > 0"
+	}
+	return getter + " != nil"
+}
+
+// isSet returns the expression of the field being set,
+// the same as protoreflect.Message.Has
+func (g *staticGen) isSet(f *protogen.Field) string {
+	getter := "m.Get" + f.GoName + "()"
+	if f.Desc.IsList() || f.Desc.IsMap() {
+		return "len(" + getter + ") > 0"
+	}
+	switch {
+	case isOneofMember(f):
+		return g.hasVar(f)
+	case f.Desc.HasPresence():
+		return "m." + f.GoName + " != nil"
+	}
+	switch f.Desc.Kind() {
+	case protoreflect.StringKind:
+		return getter + ` != ""`
+	case protoreflect.BytesKind:
+		return "len(" + getter + ") > 0"
+	case protoreflect.BoolKind:
+		return getter
+	}
+	return getter + " != 0"
+}
+
+// field writes the checks of the field
+func (g *staticGen) field(w *strings.Builder, idx int, fm *FieldMeta) {
+	f := g.protoField(fm.Name)
+	if f == nil {
+		return
+	}
+
+	g.nonEmpty = ""
+	if fm.Required && len(fm.RequiredOr) == 0 && f.Desc.Kind() == protoreflect.StringKind &&
+		f.Desc.Cardinality() != protoreflect.Repeated && !f.Desc.HasPresence() {
+		// the set oneof member and optional field can be empty
+		g.nonEmpty = "m.Get" + f.GoName + "()"
+	}
+	checks := &strings.Builder{}
+	g.checks(checks, idx, fm, f)
+	code := checks.String()
+	if code == "" && !fm.Required && len(fm.RequiredOr) == 0 && !fm.OutputOnly {
+		return
+	}
+
+	path := g.path(fm.Name)
+	required := fmt.Sprintf("if err := v.Required(fields[%d], %s); err != nil {\nreturn err\n}\n", idx, path)
+	present := g.presence(f)
+	// the value of explicit presence field is checked only if set,
+	// unset messages are not checked anyway
+	explicit := f.Desc.HasPresence() && f.Desc.Kind() != protoreflect.MessageKind && f.Desc.Kind() != protoreflect.GroupKind
+
+	if len(fm.RequiredOr) > 0 && present == "true" {
+		// the field is always present, other checks are skipped
+		code = ""
+	} else if len(fm.RequiredOr) > 0 {
+		var others []string
+		for _, name := range fm.RequiredOr {
+			if other := g.protoField(name); other != nil {
+				others = append(others, g.presence(other))
+			}
+		}
+		// none of the fields are found
+		anyOther := "false"
+		if len(others) > 0 {
+			anyOther = strings.Join(others, " || ")
+		}
+		requiredOr := fmt.Sprintf("if err := v.RequiredOr(fields[%d], %s); err != nil {\nreturn err\n}\n", idx, path)
+		// the set field is not checked, as the validator does,
+		// so only the checks of the empty value remain
+		empty := ""
+		if !explicit {
+			empty = g.emptyChecks(idx, fm, f)
+		}
+		switch {
+		case fm.Required:
+			code = fmt.Sprintf("if %s {\nif %s {\n%s} else {\n%s}\n}\n", negate(present), negate(anyOther), requiredOr, required)
+		case empty == "":
+			code = fmt.Sprintf("if %s && %s {\n%s}\n", negate(present), negate(anyOther), requiredOr)
+		default:
+			code = fmt.Sprintf("if %s {\nif %s {\n%s} else {\n%s}\n}\n", negate(present), negate(anyOther), requiredOr, empty)
+		}
+	} else if fm.Required && present != "true" && code == "" {
+		code = fmt.Sprintf("if %s {\n%s}\n", negate(present), required)
+	} else if fm.Required && present != "true" {
+		code = fmt.Sprintf("if %s {\n%s} else {\n%s}\n", negate(present), required, code)
+	} else if explicit && code != "" {
+		code = fmt.Sprintf("if %s {\n%s}\n", present, code)
+	}
+	if fm.OutputOnly {
+		// the output only field is rejected in the requests, and validated in the responses
+		outputOnly := fmt.Sprintf("if %s {\nif err := v.OutputOnly(fields[%d], %s); err != nil {\nreturn err\n}\n}\n", g.isSet(f), idx, path)
+		if code == "" {
+			code = fmt.Sprintf("if !v.IsResponse() {\n%s}\n", outputOnly)
+		} else {
+			code = fmt.Sprintf("if !v.IsResponse() {\n%s} else {\n%s}\n", outputOnly, code)
+		}
+	}
+	if code == "" {
+		return
+	}
+
+	fmt.Fprintf(w, "// %s\n", fm.Name)
+	if isOneofMember(f) {
+		// only the set member of oneof is validated
+		fmt.Fprintf(w, "if m.%s == nil || %s {\n%s}\n", f.Oneof.GoName, g.hasVar(f), code)
+		return
+	}
+	w.WriteString(code)
+}
+
+// emptyChecks returns the checks, that fail for the empty value
+// of string, bytes, list, map or message field,
+// the same as the validator reports for the unset field.
+func (g *staticGen) emptyChecks(idx int, fm *FieldMeta, f *protogen.Field) string {
+	w := &strings.Builder{}
+	meta := fmt.Sprintf("fields[%d]", idx)
+	path := g.path(fm.Name)
+	report := func(check string, args ...string) {
+		fmt.Fprintf(w, "if err := v.%s(%s); err != nil {\nreturn err\n}\n", check, strings.Join(append([]string{meta, path}, args...), ", "))
+	}
+
+	switch {
+	case f.Desc.IsList() || f.Desc.IsMap():
+		if fm.MinCount > 0 {
+			fmt.Fprintf(w, "if err := v.Count(0, %s, %s); err != nil {\nreturn err\n}\n", meta, path)
+		}
+	case f.Desc.Kind() == protoreflect.StringKind:
+		if fm.Min > 0 {
+			report("MinLength", "0")
+		}
+		if len(fm.In) > 0 && !slices.Contains(fm.In, "") {
+			report("In", `""`)
+		}
+		if slices.Contains(fm.NotIn, "") {
+			report("NotIn", `""`)
+		}
+	case f.Desc.Kind() == protoreflect.BytesKind:
+		if fm.Min > 0 {
+			report("MinLength", "0")
+		}
+	}
+	return w.String()
+}
+
+// checks writes the constraints checks of the value
+func (g *staticGen) checks(w *strings.Builder, idx int, fm *FieldMeta, f *protogen.Field) {
+	getter := "m.Get" + f.GoName + "()"
+	meta := fmt.Sprintf("fields[%d]", idx)
+	path := g.path(fm.Name)
+
+	switch {
+	case f.Desc.IsList():
+		if fm.MinCount > 0 || fm.MaxCount > 0 {
+			fmt.Fprintf(w, "if err := v.Count(len(%s), %s, %s); err != nil {\nreturn err\n}\n", getter, meta, path)
+		}
+		if fm.Unique {
+			fmt.Fprintf(w, "if err := api.ValidateUnique(v, %s, %s, %s); err != nil {\nreturn err\n}\n", getter, meta, path)
+		}
+		itemPath := concat(path, `"["`, "strconv.Itoa(i)", `"]"`)
+		if target := g.s.target(fm, f); target != nil {
+			// the items are validated, when the nested fields are populated
+			fmt.Fprintf(w, "if len(%s.Fields) > 0 {\nfor i, item := range %s {\n", meta, getter)
+			g.nestedCall(w, target, "item", meta, itemPath)
+			w.WriteString("}\n}\n")
+			return
+		}
+		item := &strings.Builder{}
+		g.value(item, itemRules(fm), f, "item", meta+".ItemMeta()", itemPath, "pattern"+g.md.Name+f.GoName+"Items")
+		if item.Len() > 0 {
+			fmt.Fprintf(w, "for i, item := range %s {\n%s}\n", getter, item.String())
+		}
+	case f.Desc.IsMap():
+		keyField := f.Message.Fields[0]
+		valField := f.Message.Fields[1]
+		if fm.MinCount > 0 || fm.MaxCount > 0 {
+			fmt.Fprintf(w, "if err := v.Count(len(%s), %s, %s); err != nil {\nreturn err\n}\n", getter, meta, path)
+		}
+
+		keyString := keyString(keyField.Desc.Kind(), "key")
+		key := &strings.Builder{}
+		if fm.Keys != nil {
+			g.value(key, ruleSet(fm.Keys, nil), keyField, "key", meta+".KeyMeta()", concat(path, `".keys["`, keyString, `"]"`), "pattern"+g.md.Name+f.GoName+"Keys")
+		}
+		val := &strings.Builder{}
+		g.value(val, fieldRules(fm), valField, "val", meta, concat(path, `"["`, keyString, `"]"`), "pattern"+g.md.Name+f.GoName)
+		switch {
+		case val.Len() > 0:
+			fmt.Fprintf(w, "for key, val := range %s {\n%s%s}\n", getter, key.String(), val.String())
+		case key.Len() > 0:
+			fmt.Fprintf(w, "for key := range %s {\n%s}\n", getter, key.String())
+		}
+	default:
+		g.value(w, fieldRules(fm), f, getter, meta, path, "pattern"+g.md.Name+f.GoName)
+		if target := g.s.target(fm, f); target != nil {
+			// the nested message is validated, when the nested fields are populated
+			fmt.Fprintf(w, "if len(%s.Fields) > 0 && %s != nil {\n", meta, getter)
+			g.nestedCall(w, target, getter, meta, path)
+			w.WriteString("}\n")
+		}
+	}
+}
+
+// nestedCall writes the validation of the nested message fields
+func (g *staticGen) nestedCall(w *strings.Builder, target *MessageDescription, expr, meta, path string) {
+	if target.Package != g.s.pkg {
+		// the message of another package has no generated validator
+		fmt.Fprintf(w, "if err := v.Nested(%s, %s, %s); err != nil {\nreturn err\n}\n", expr, meta, path)
+		return
+	}
+	fmt.Fprintf(w, "if err := validate%sFields(v, %s, %s); err != nil {\nreturn err\n}\n", target.Name, expr, concat(path, `"."`))
+}
+
+// valueRules are the constraints of the field value,
+// or of the items and keys of the field.
+type valueRules struct {
+	Min, Max                        int32
+	Pattern, Prefix, Suffix, Format string
+	In, NotIn                       []string
+	Range                           *api.NumberRange
+	TimeRange                       *api.TimeRange
+	// Enum is the description of the enum, available at runtime
+	Enum *EnumDescription
+	// Limits is true, if min and max are the limits of the numbers
+	Limits bool
+}
+
+func fieldRules(fm *FieldMeta) *valueRules {
+	return &valueRules{
+		Min:       fm.Min,
+		Max:       fm.Max,
+		Pattern:   fm.Pattern,
+		Prefix:    fm.Prefix,
+		Suffix:    fm.Suffix,
+		Format:    fm.Format,
+		In:        fm.In,
+		NotIn:     fm.NotIn,
+		Range:     fm.Range,
+		TimeRange: fm.TimeRange,
+		Enum:      runtimeEnum(fm),
+		Limits:    true,
+	}
+}
+
+// itemRules returns es.api.items rules,
+// the enum items are checked to be defined without the rules.
+func itemRules(fm *FieldMeta) *valueRules {
+	return ruleSet(fm.Items, runtimeEnum(fm))
+}
+
+func ruleSet(x *api.ItemRules, ed *EnumDescription) *valueRules {
+	return &valueRules{
+		Min:     x.GetMin(),
+		Max:     x.GetMax(),
+		Pattern: x.GetPattern(),
+		Prefix:  x.GetPrefix(),
+		Suffix:  x.GetSuffix(),
+		Format:  x.GetFormat(),
+		In:      x.GetIn(),
+		NotIn:   x.GetNotIn(),
+		Range:   x.GetRange(),
+		Enum:    ed,
+	}
+}
+
+// runtimeEnum returns EnumDescription of the field,
+// if it is set in the generated FieldMeta
+func runtimeEnum(fm *FieldMeta) *EnumDescription {
+	if fm.EnumDescriptionName == "" {
+		return nil
+	}
+	return fm.EnumDescription
+}
+
+// value writes the checks of the value expression,
+// in the same order as the validator checks them,
+// pattern is the name of the compiled es.api.pattern variable.
+func (g *staticGen) value(w *strings.Builder, r *valueRules, f *protogen.Field, expr, meta, path, pattern string) {
+	report := func(w *strings.Builder, cond, check string, args ...string) {
+		call := fmt.Sprintf("if err := v.%s(%s); err != nil {\nreturn err\n}\n", check, strings.Join(append([]string{meta, path}, args...), ", "))
+		writeIf(w, cond, call)
+	}
+
+	kind := f.Desc.Kind()
+	switch kind {
+	case protoreflect.StringKind:
+		g.length(w, r, "len("+expr+")", report)
+		str := &strings.Builder{}
+		if r.Prefix != "" {
+			report(str, fmt.Sprintf("!strings.HasPrefix(%s, %q)", expr, r.Prefix), "Prefix")
+		}
+		if r.Suffix != "" {
+			report(str, fmt.Sprintf("!strings.HasSuffix(%s, %q)", expr, r.Suffix), "Suffix")
+		}
+		if r.Pattern != "" {
+			g.s.patterns[pattern] = r.Pattern
+			report(str, fmt.Sprintf("!%s.MatchString(%s)", pattern, expr), "Pattern")
+		}
+		if r.Format != "" {
+			report(str, fmt.Sprintf("!api.IsValidStringFormat(%q, %s)", r.Format, expr), "Format")
+		}
+		switch {
+		case str.Len() == 0:
+		case expr == g.nonEmpty:
+			w.WriteString(str.String())
+		default:
+			// empty values are checked by required option
+			fmt.Fprintf(w, "if %s != \"\" {\n%s}\n", expr, str.String())
+		}
+		var in, notIn []string
+		for _, s := range r.In {
+			in = append(in, strconv.Quote(s))
+		}
+		for _, s := range r.NotIn {
+			notIn = append(notIn, strconv.Quote(s))
+		}
+		g.in(w, r, expr, expr, in, notIn, report)
+	case protoreflect.BytesKind:
+		g.length(w, r, "len("+expr+")", report)
+	case protoreflect.EnumKind:
+		g.enum(w, r, "int32("+expr+")", report)
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if r.TimeRange == nil {
+			return
+		}
+		var check string
+		switch f.Message.Desc.FullName() {
+		case "google.protobuf.Timestamp":
+			check = "Timestamp"
+		case "google.protobuf.Duration":
+			check = "Duration"
+		default:
+			return
+		}
+		fmt.Fprintf(w, "if t := %s; t != nil {\n", expr)
+		report(w, "true", check, "t.GetSeconds()", "int64(t.GetNanos())")
+		w.WriteString("}\n")
+	case protoreflect.BoolKind:
+	default:
+		g.number(w, r, kind, expr, report)
+	}
+}
+
+type reportFunc func(w *strings.Builder, cond, check string, args ...string)
+
+// length writes min and max checks of the length
+func (g *staticGen) length(w *strings.Builder, r *valueRules, length string, report reportFunc) {
+	minLen := &strings.Builder{}
+	maxLen := &strings.Builder{}
+	if r.Min > 0 {
+		report(minLen, "true", "MinLength", length)
+	}
+	if r.Max > 0 {
+		report(maxLen, "true", "MaxLength", length)
+	}
+	writeIfElse(w, r.Min > 0, fmt.Sprintf("%s < %d", length, r.Min), minLen.String(),
+		r.Max > 0, fmt.Sprintf("%s > %d", length, r.Max), maxLen.String())
+}
+
+// in writes in and not_in checks, values are the Go literals
+func (g *staticGen) in(w *strings.Builder, r *valueRules, expr, actual string, in, notIn []string, report reportFunc) {
+	if len(r.In) > 0 {
+		cond := "true"
+		if len(in) > 0 {
+			conds := make([]string, len(in))
+			for i, val := range in {
+				conds[i] = expr + " != " + val
+			}
+			cond = strings.Join(conds, " && ")
+		}
+		report(w, cond, "In", actual)
+	}
+	if len(notIn) > 0 {
+		conds := make([]string, len(notIn))
+		for i, val := range notIn {
+			conds[i] = expr + " == " + val
+		}
+		report(w, strings.Join(conds, " || "), "NotIn", actual)
+	}
+}
+
+// enum writes the checks of the enum number
+func (g *staticGen) enum(w *strings.Builder, r *valueRules, num string, report reportFunc) {
+	ed := r.Enum
+	isBitmask := ed != nil && ed.IsBitmask
+
+	in := &strings.Builder{}
+	if len(r.In) > 0 {
+		values := enumValues(ed, r.In)
+		if isBitmask {
+			report(in, fmt.Sprintf("%s&^%d != 0", num, union(values)), "In", num)
+		} else {
+			g.in(in, &valueRules{In: r.In}, num, num, intLiterals(values), nil, report)
+		}
+	}
+	if len(r.NotIn) > 0 {
+		values := enumValues(ed, r.NotIn)
+		if isBitmask {
+			if u := union(values); u != 0 {
+				report(in, fmt.Sprintf("%s&%d != 0", num, u), "NotIn", num)
+			}
+		} else {
+			g.in(in, &valueRules{}, num, num, nil, intLiterals(values), report)
+		}
+	}
+
+	switch {
+	case isBitmask:
+		var flags int32
+		for _, enum := range ed.Enums {
+			flags |= enum.Value
+		}
+		fmt.Fprintf(w, "if invalid := %s &^ %d; invalid != 0 {\n", num, flags)
+		report(w, "true", "EnumFlags", num, "invalid")
+		if in.Len() > 0 {
+			// the value is reported, do not check in/not_in
+			fmt.Fprintf(w, "} else {\n%s", in.String())
+		}
+		w.WriteString("}\n")
+	case ed != nil:
+		var defined []int32
+		for _, enum := range ed.Enums {
+			if !containsInt32(defined, enum.Value) {
+				defined = append(defined, enum.Value)
+			}
+		}
+		fmt.Fprintf(w, "switch %s {\n", num)
+		if len(defined) > 0 {
+			fmt.Fprintf(w, "case %s:\n%s", strings.Join(intLiterals(defined), ", "), in.String())
+		}
+		w.WriteString("default:\n")
+		report(w, "true", "Enum", num)
+		w.WriteString("}\n")
+	default:
+		w.WriteString(in.String())
+	}
+}
+
+// number writes min, max, range, in and not_in checks of the number
+func (g *staticGen) number(w *strings.Builder, r *valueRules, kind protoreflect.Kind, expr string, report reportFunc) {
+	signed, unsigned, float := numberKind(kind)
+	if !signed && !unsigned && !float {
+		return
+	}
+	// the value is widened, as the validator compares it
+	wide := expr
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		wide = "int64(" + expr + ")"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		wide = "uint64(" + expr + ")"
+	case protoreflect.FloatKind:
+		wide = "float64(" + expr + ")"
+	}
+
+	if r.Limits {
+		minVal := &strings.Builder{}
+		maxVal := &strings.Builder{}
+		var checkMin, checkMax bool
+		switch kind {
+		case protoreflect.Int32Kind, protoreflect.Int64Kind:
+			checkMin, checkMax = r.Min != 0, r.Max != 0
+		case protoreflect.Uint32Kind, protoreflect.Uint64Kind, protoreflect.Fixed32Kind, protoreflect.Fixed64Kind,
+			protoreflect.FloatKind, protoreflect.DoubleKind:
+			checkMin, checkMax = r.Min > 0, r.Max > 0
+		}
+		if checkMin {
+			report(minVal, "true", "Min", wide)
+		}
+		if checkMax {
+			report(maxVal, "true", "Max", wide)
+		}
+		writeIfElse(w, checkMin, fmt.Sprintf("%s < %d", wide, r.Min), minVal.String(),
+			checkMax, fmt.Sprintf("%s > %d", wide, r.Max), maxVal.String())
+	}
+
+	if lower := r.Range.GetMin(); lower.GetValue() != nil {
+		report(w, rangeCond(wide, signed, unsigned, lower, true), "RangeMin", expr)
+	}
+	if upper := r.Range.GetMax(); upper.GetValue() != nil {
+		report(w, rangeCond(wide, signed, unsigned, upper, false), "RangeMax", expr)
+	}
+
+	if float || (len(r.In) == 0 && len(r.NotIn) == 0) {
+		return
+	}
+	bits := 64
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		bits = 32
+	}
+	// the validator compares the canonical format of the number
+	values := func(tokens []string) []string {
+		var res []string
+		for _, token := range tokens {
+			if signed {
+				if n, err := strconv.ParseInt(token, 10, bits); err == nil && strconv.FormatInt(n, 10) == token {
+					res = append(res, token)
+				}
+			} else if n, err := strconv.ParseUint(token, 10, bits); err == nil && strconv.FormatUint(n, 10) == token {
+				res = append(res, token)
+			}
+		}
+		return res
+	}
+	actual := "strconv.FormatInt(" + wide + ", 10)"
+	if unsigned {
+		actual = "strconv.FormatUint(" + wide + ", 10)"
+	}
+	g.in(w, r, expr, actual, values(r.In), values(r.NotIn), report)
+}
+
+// numberKind returns the kind of the number
+func numberKind(kind protoreflect.Kind) (signed, unsigned, float bool) {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return true, false, false
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return false, true, false
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return false, false, true
+	}
+	return false, false, false
+}
+
+// rangeCond returns the condition of the widened value out of the bound,
+// the same as NumberBound comparison of the validator.
+func rangeCond(wide string, signed, unsigned bool, b *api.NumberBound, lower bool) string {
+	// out of the bound, if the value is less (lower) or greater (upper)
+	op := map[bool]string{true: "<", false: ">"}[lower]
+	if b.Exclusive {
+		op += "="
+	}
+	floatCond := func(x, bound string) string {
+		// NaN is out of any bound
+		inside := map[bool]string{true: ">", false: "<"}[lower]
+		if !b.Exclusive {
+			inside += "="
+		}
+		return fmt.Sprintf("!(%s %s %s)", x, inside, bound)
+	}
+
+	switch v := b.GetValue().(type) {
+	case *api.NumberBound_Int:
+		switch {
+		case signed:
+			return fmt.Sprintf("%s %s %d", wide, op, v.Int)
+		case unsigned:
+			if v.Int < 0 {
+				// any unsigned value is greater than the bound
+				return strconv.FormatBool(!lower)
+			}
+			return fmt.Sprintf("%s %s %d", wide, op, v.Int)
+		}
+		return floatCond(wide, strconv.FormatInt(v.Int, 10))
+	case *api.NumberBound_Uint:
+		switch {
+		case unsigned:
+			return fmt.Sprintf("%s %s %d", wide, op, v.Uint)
+		case signed:
+			if v.Uint > math.MaxInt64 {
+				// any signed value is less than the bound
+				return strconv.FormatBool(lower)
+			}
+			return fmt.Sprintf("%s %s %d", wide, op, v.Uint)
+		}
+		return floatCond(wide, strconv.FormatUint(v.Uint, 10))
+	case *api.NumberBound_Float:
+		if signed || unsigned {
+			wide = "float64(" + wide + ")"
+		}
+		return floatCond(wide, floatLiteral(v.Float))
+	}
+	return "false"
+}
+
+// floatLiteral returns Go expression of the float64 value
+func floatLiteral(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "math.NaN()"
+	case math.IsInf(f, 1):
+		return "math.Inf(1)"
+	case math.IsInf(f, -1):
+		return "math.Inf(-1)"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// enumValues returns the enum numbers of the tokens,
+// the same as EnumDescription values of the validator.
+func enumValues(ed *EnumDescription, tokens []string) []int32 {
+	res := make([]int32, 0, len(tokens))
+	for _, token := range tokens {
+		if n, err := strconv.ParseInt(token, 10, 32); err == nil {
+			res = append(res, int32(n))
+		} else if ed != nil {
+			for _, enum := range ed.Enums {
+				if enum.Name == token || enum.FullName == token || enum.Display == token {
+					res = append(res, enum.Value)
+					break
+				}
+			}
+		}
+	}
+	return res
+}
+
+func union(values []int32) int32 {
+	var res int32
+	for _, v := range values {
+		res |= v
+	}
+	return res
+}
+
+func containsInt32(values []int32, v int32) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func intLiterals(values []int32) []string {
+	res := make([]string, len(values))
+	for i, v := range values {
+		res[i] = strconv.FormatInt(int64(v), 10)
+	}
+	return res
+}
+
+// writeIf writes the code, if the condition is not false
+func writeIf(w *strings.Builder, cond, code string) {
+	switch cond {
+	case "false":
+	case "true":
+		w.WriteString(code)
+	default:
+		fmt.Fprintf(w, "if %s {\n%s}\n", cond, code)
+	}
+}
+
+// writeIfElse writes exclusive checks of min and max
+func writeIfElse(w *strings.Builder, checkMin bool, minCond, minCode string, checkMax bool, maxCond, maxCode string) {
+	switch {
+	case checkMin && checkMax:
+		fmt.Fprintf(w, "if %s {\n%s} else if %s {\n%s}\n", minCond, minCode, maxCond, maxCode)
+	case checkMin:
+		writeIf(w, minCond, minCode)
+	case checkMax:
+		writeIf(w, maxCond, maxCode)
+	}
+}
+
+// concat returns the expression of the string concatenation,
+// the adjacent string literals are merged
+func concat(exprs ...string) string {
+	res := exprs[0]
+	for _, expr := range exprs[1:] {
+		if strings.HasSuffix(res, `"`) && strings.HasPrefix(expr, `"`) {
+			res = res[:len(res)-1] + expr[1:]
+		} else {
+			res += "+" + expr
+		}
+	}
+	return res
+}
+
+// keyString returns the expression of the map key in the field path,
+// the same as protoreflect.MapKey.String
+func keyString(kind protoreflect.Kind, expr string) string {
+	switch kind {
+	case protoreflect.StringKind:
+		return expr
+	case protoreflect.BoolKind:
+		return "strconv.FormatBool(" + expr + ")"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "strconv.FormatUint(uint64(" + expr + "), 10)"
+	}
+	return "strconv.FormatInt(int64(" + expr + "), 10)"
+}
+
+// negate returns the negated presence expression
+func negate(expr string) string {
+	switch {
+	case expr == "false":
+		return "true"
+	case strings.Contains(expr, "||"):
+		return "!(" + expr + ")"
+	case strings.HasSuffix(expr, " != nil"):
+		return strings.TrimSuffix(expr, " != nil") + " == nil"
+	case strings.HasSuffix(expr, ` != ""`):
+		return strings.TrimSuffix(expr, ` != ""`) + ` == ""`
+	case strings.HasSuffix(expr, " > 0"):
+		return strings.TrimSuffix(expr, " > 0") + " == 0"
+	}
+	return "!" + expr
+}
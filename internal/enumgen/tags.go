@@ -0,0 +1,200 @@
+package enumgen
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/effective-security/protoc-gen-go/api"
+	"github.com/effective-security/x/format"
+	"github.com/effective-security/x/values"
+)
+
+// SupportedModelTags are the struct tags of the models,
+// that can be generated in addition to json tag
+var SupportedModelTags = []string{"yaml", "db", "bson", "validate"}
+
+// validateFormats are the formats, supported by the validate tag
+var validateFormats = map[string]bool{
+	api.FormatEmail:    true,
+	api.FormatURI:      true,
+	api.FormatHostname: true,
+	api.FormatIP:       true,
+	api.FormatIPv4:     true,
+	api.FormatIPv6:     true,
+	api.FormatUUID:     true,
+	api.FormatULID:     true,
+}
+
+var structTagRegex = regexp.MustCompile(`^([^\s:"]+):("(?:[^"\\]|\\.)*")\s*`)
+
+// structTag is a key and value of the struct tag
+type structTag struct {
+	Key   string
+	Value string
+}
+
+// ParseModelTags returns the unique list of SupportedModelTags,
+// each value can be a comma-separated list of the tags.
+func ParseModelTags(list []string) ([]string, error) {
+	var tags []string
+	for _, tag := range strings.Split(strings.Join(list, ","), ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || slices.Contains(tags, tag) {
+			continue
+		}
+		if !slices.Contains(SupportedModelTags, tag) {
+			return nil, errors.Errorf("unsupported model tag: %s", tag)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// parseStructTags parses the tags in the struct tag format:
+// key:"value" key2:"value2"
+func parseStructTags(s string) ([]structTag, error) {
+	var tags []structTag
+	s = strings.TrimSpace(s)
+	for s != "" {
+		m := structTagRegex.FindStringSubmatch(s)
+		if m == nil {
+			return nil, errors.Errorf("invalid struct tag: %s", s)
+		}
+		val, err := strconv.Unquote(m[2])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid struct tag value: %s", m[2])
+		}
+		tags = append(tags, structTag{Key: m[1], Value: val})
+		s = s[len(m[0]):]
+	}
+	return tags, nil
+}
+
+// modelTags returns the struct tags of the model field:
+// json tag, the tags of opts.ModelTags, and es.api.model_tags of the field,
+// which override the generated tags with the same key.
+func modelTags(field *FieldMeta, opts Opts) string {
+	tags := []structTag{{Key: "json", Value: field.Name + ",omitempty"}}
+	for _, key := range opts.ModelTags {
+		var val string
+		switch key {
+		case "yaml":
+			val = field.Name + ",omitempty"
+		case "db":
+			val = values.StringsCoalesce(field.Alias, snakeCase(field.Name))
+		case "bson":
+			if field.SearchOptions&api.SearchOption_Exclude != 0 {
+				// the field is not stored in the documents
+				val = "-"
+			} else {
+				val = values.StringsCoalesce(field.Alias, field.Name) + ",omitempty"
+			}
+		case "validate":
+			val = validateTag(field)
+		}
+		if val != "" {
+			tags = append(tags, structTag{Key: key, Value: val})
+		}
+	}
+
+	// the tags are validated by fieldMeta
+	custom, _ := parseStructTags(field.ModelTags)
+	for _, tag := range custom {
+		i := slices.IndexFunc(tags, func(t structTag) bool { return t.Key == tag.Key })
+		if i >= 0 {
+			tags[i].Value = tag.Value
+		} else {
+			tags = append(tags, tag)
+		}
+	}
+
+	res := make([]string, len(tags))
+	for i, tag := range tags {
+		res[i] = fmt.Sprintf("%s:%s", tag.Key, strconv.Quote(tag.Value))
+	}
+	return strings.Join(res, " ")
+}
+
+// snakeCase returns the snake_case name of the field,
+// the digits are kept with the preceding word:
+// "Uint64Value" => "uint64_value", "RefIDs" => "ref_ids"
+func snakeCase(name string) string {
+	if strings.Contains(name, "_") {
+		return strings.ToLower(name)
+	}
+	var words []string
+	for _, word := range format.Split(name) {
+		if len(words) > 0 && word[0] >= '0' && word[0] <= '9' {
+			words[len(words)-1] += word
+			continue
+		}
+		words = append(words, strings.ToLower(word))
+	}
+	return strings.Join(words, "_")
+}
+
+// validateTag returns the validate tag of the field
+// with the field rules, or empty string if the field has no rules.
+func validateTag(field *FieldMeta) string {
+	var rules []string
+	switch {
+	case field.Type == "string" || field.Type == "[]byte":
+		if field.Min > 0 {
+			rules = append(rules, fmt.Sprintf("min=%d", field.Min))
+		}
+		if field.Max > 0 {
+			rules = append(rules, fmt.Sprintf("max=%d", field.Max))
+		}
+	case field.Type == "map" || strings.HasPrefix(field.Type, "[]"):
+		if field.MinCount > 0 {
+			rules = append(rules, fmt.Sprintf("min=%d", field.MinCount))
+		}
+		if field.MaxCount > 0 {
+			rules = append(rules, fmt.Sprintf("max=%d", field.MaxCount))
+		}
+		if field.Unique && field.Type != "[]struct" {
+			rules = append(rules, "unique")
+		}
+	case field.EnumDescription == nil && field.Type != "bool" && field.Type != "struct":
+		// the negative limits are checked for the signed numbers
+		if field.Min != 0 {
+			rules = append(rules, fmt.Sprintf("min=%d", field.Min))
+		}
+		if field.Max != 0 {
+			rules = append(rules, fmt.Sprintf("max=%d", field.Max))
+		}
+	}
+	if field.Type == "string" {
+		if validateFormats[field.Format] {
+			rules = append(rules, field.Format)
+		}
+		if isTagParam(field.Prefix) {
+			rules = append(rules, "startswith="+field.Prefix)
+		}
+		if isTagParam(field.Suffix) {
+			rules = append(rules, "endswith="+field.Suffix)
+		}
+	}
+	if len(field.In) > 0 && field.EnumDescription == nil && (field.Type == "string" || strings.HasPrefix(field.Type, "int") || strings.HasPrefix(field.Type, "uint")) {
+		if !slices.ContainsFunc(field.In, func(v string) bool { return !isTagParam(v) || strings.Contains(v, " ") }) {
+			rules = append(rules, "oneof="+strings.Join(field.In, " "))
+		}
+	}
+
+	switch {
+	case field.Required:
+		rules = append([]string{"required"}, rules...)
+	case len(rules) > 0:
+		rules = append([]string{"omitempty"}, rules...)
+	}
+	return strings.Join(rules, ",")
+}
+
+// isTagParam returns true, if the value can be used as the validate tag parameter
+func isTagParam(val string) bool {
+	return val != "" && !strings.ContainsAny(val, `,|"`)
+}
@@ -0,0 +1,128 @@
+package enumgen
+
+import (
+	"testing"
+
+	"github.com/effective-security/protoc-gen-go/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseModelTags(t *testing.T) {
+	tags, err := ParseModelTags(nil)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+
+	tags, err = ParseModelTags([]string{"yaml", "db, bson", "yaml", "validate"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"yaml", "db", "bson", "validate"}, tags)
+
+	_, err = ParseModelTags([]string{"xml"})
+	assert.EqualError(t, err, "unsupported model tag: xml")
+}
+
+func Test_parseStructTags(t *testing.T) {
+	tags, err := parseStructTags(` db:"created_at"  bson:"-" validate:"omitempty,oneof=a b" esc:"\"q\""`)
+	require.NoError(t, err)
+	assert.Equal(t, []structTag{
+		{Key: "db", Value: "created_at"},
+		{Key: "bson", Value: "-"},
+		{Key: "validate", Value: "omitempty,oneof=a b"},
+		{Key: "esc", Value: `"q"`},
+	}, tags)
+
+	for _, s := range []string{`db`, `db:created_at`, `db:"created_at`, `:"x"`} {
+		_, err = parseStructTags(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func Test_snakeCase(t *testing.T) {
+	tests := map[string]string{
+		"ID":            "id",
+		"Name":          "name",
+		"resource_type": "resource_type",
+		"Uint64Value":   "uint64_value",
+		"RefIDs":        "ref_ids",
+		"AssetID":       "asset_id",
+		"createdAt":     "created_at",
+	}
+	for name, exp := range tests {
+		assert.Equal(t, exp, snakeCase(name), name)
+	}
+}
+
+func Test_modelTags(t *testing.T) {
+	all := Opts{ModelTags: SupportedModelTags}
+	tests := []struct {
+		name  string
+		field *FieldMeta
+		opts  Opts
+		exp   string
+	}{
+		{
+			name:  "json",
+			field: &FieldMeta{Name: "CreatedAt", Type: "string", Required: true},
+			exp:   `json:"CreatedAt,omitempty"`,
+		},
+		{
+			name:  "all",
+			field: &FieldMeta{Name: "CreatedAt", Type: "string", Required: true},
+			opts:  all,
+			exp:   `json:"CreatedAt,omitempty" yaml:"CreatedAt,omitempty" db:"created_at" bson:"CreatedAt,omitempty" validate:"required"`,
+		},
+		{
+			name:  "alias",
+			field: &FieldMeta{Name: "CreatedAt", Alias: "created", Type: "string"},
+			opts:  Opts{ModelTags: []string{"db", "bson"}},
+			exp:   `json:"CreatedAt,omitempty" db:"created" bson:"created,omitempty"`,
+		},
+		{
+			name:  "exclude",
+			field: &FieldMeta{Name: "data", Type: "[]byte", SearchOptions: api.SearchOption_Exclude | api.SearchOption_NoIndex},
+			opts:  Opts{ModelTags: []string{"bson", "db"}},
+			exp:   `json:"data,omitempty" bson:"-" db:"data"`,
+		},
+		{
+			name:  "custom",
+			field: &FieldMeta{Name: "ID", Type: "string", ModelTags: `bson:"_id" xml:"id,attr"`},
+			opts:  Opts{ModelTags: []string{"bson"}},
+			exp:   `json:"ID,omitempty" bson:"_id" xml:"id,attr"`,
+		},
+		{
+			name:  "string",
+			field: &FieldMeta{Name: "email", Type: "string", Min: 3, Max: 64, Format: "email", Prefix: "a", Suffix: ",", In: []string{"a@b.c", "c@d.e"}},
+			opts:  Opts{ModelTags: []string{"validate"}},
+			exp:   `json:"email,omitempty" validate:"omitempty,min=3,max=64,email,startswith=a,oneof=a@b.c c@d.e"`,
+		},
+		{
+			name:  "signed",
+			field: &FieldMeta{Name: "delta", Type: "int32", Min: -10, Max: 10, Optional: true},
+			opts:  Opts{ModelTags: []string{"validate"}},
+			exp:   `json:"delta,omitempty" validate:"omitempty,min=-10,max=10"`,
+		},
+		{
+			name:  "enum",
+			field: &FieldMeta{Name: "type", Type: "int32", Min: 1, In: []string{"Foo"}, EnumDescription: &EnumDescription{Name: "Type"}},
+			opts:  Opts{ModelTags: []string{"validate"}},
+			exp:   `json:"type,omitempty"`,
+		},
+		{
+			name:  "list",
+			field: &FieldMeta{Name: "values", Type: "[]string", Min: 2, MinCount: 1, MaxCount: 5, Unique: true},
+			opts:  Opts{ModelTags: []string{"validate"}},
+			exp:   `json:"values,omitempty" validate:"omitempty,min=1,max=5,unique"`,
+		},
+		{
+			name:  "map",
+			field: &FieldMeta{Name: "map", Type: "map", MaxCount: 2, Required: true},
+			opts:  Opts{ModelTags: []string{"validate"}},
+			exp:   `json:"map,omitempty" validate:"required,max=2"`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.exp, modelTags(tc.field, tc.opts))
+		})
+	}
+}
@@ -30,6 +30,12 @@ type Opts struct {
 	// Package provides package name
 	Package      string
 	ModelPackage string
+	// StaticValidate specifies to generate Validate methods with the static
+	// checks, instead of calling api.ValidateRequest
+	StaticValidate bool
+	// ModelTags are the additional struct tags of the models,
+	// see SupportedModelTags
+	ModelTags []string
 }
 
 // This function is called with a param which contains the entire definition of a method.
@@ -136,8 +142,9 @@ func ApplyEnums(w io.Writer, opts Opts, enums []*EnumDescription) error {
 
 func ApplyMessages(w io.Writer, opts Opts, msgs []*MessageDescription) error {
 	mt := tplMessagesMap{
-		Package:      opts.Package,
-		Descriptions: msgs,
+		Package:        opts.Package,
+		StaticValidate: opts.StaticValidate,
+		Descriptions:   msgs,
 	}
 
 	sort.Slice(mt.Descriptions, func(i, j int) bool {
@@ -197,30 +204,104 @@ func generateGoModels(w io.Writer, opts Opts, msgs []*MessageDescription, seen m
 		if err != nil {
 			return errors.Wrapf(err, "failed to write model: %s", md.FullName)
 		}
+		_, _ = fmt.Fprint(w, modelConverters(md, opts))
 
 		for _, field := range md.Fields {
-			if field.Type == "struct" || field.Type == "[]struct" {
-				md := &MessageDescription{
-					Name:     field.StructName,
-					Fields:   field.Fields,
-					FullName: field.StructName,
-				}
-				if md.Fields == nil {
-					def := messageDescriptions[field.StructName]
-					if def != nil {
-						md.Fields = def.Fields
-					}
-				}
-				err := generateGoModels(w, opts, []*MessageDescription{md}, seen)
-				if err != nil {
-					return err
+			nested := field
+			switch field.Type {
+			case "struct", "[]struct":
+			case "map":
+				def := modelFields(field)
+				if len(def) != 2 || def[1].Type != "struct" {
+					continue
 				}
+				nested = def[1]
+			default:
+				continue
+			}
+			if _, ok := wellKnownModelType(nested.StructName); ok {
+				continue
+			}
+			fields := modelFields(nested)
+			if fields == nil {
+				continue
+			}
+			nmd := &MessageDescription{
+				Name:     modelName(nested.StructName),
+				Fields:   fields,
+				FullName: nested.StructName,
+			}
+			err := generateGoModels(w, opts, []*MessageDescription{nmd}, seen)
+			if err != nil {
+				return err
 			}
 		}
 	}
 	return nil
 }
 
+// wellKnownModelTypes maps the well-known types to the Go types of the models
+var wellKnownModelTypes = map[string]string{
+	"google.protobuf.Timestamp":   "time.Time",
+	"google.protobuf.Duration":    "*time.Duration",
+	"google.protobuf.Struct":      "map[string]any",
+	"google.protobuf.Value":       "any",
+	"google.protobuf.ListValue":   "[]any",
+	"google.protobuf.DoubleValue": "*float64",
+	"google.protobuf.FloatValue":  "*float32",
+	"google.protobuf.Int64Value":  "*int64",
+	"google.protobuf.UInt64Value": "*uint64",
+	"google.protobuf.Int32Value":  "*int32",
+	"google.protobuf.UInt32Value": "*uint32",
+	"google.protobuf.BoolValue":   "*bool",
+	"google.protobuf.StringValue": "*string",
+	"google.protobuf.BytesValue":  "[]byte",
+}
+
+// wellKnownModelType returns the Go type of the model for the google message,
+// the models are not generated for google messages.
+func wellKnownModelType(name string) (string, bool) {
+	if typ, ok := wellKnownModelTypes[name]; ok {
+		return typ, true
+	}
+	if strings.HasPrefix(name, "google.") {
+		return "json.RawMessage", true
+	}
+	return "", false
+}
+
+// modelFields returns the fields of the nested message,
+// or nil if the definition is not found
+func modelFields(field *FieldMeta) []*FieldMeta {
+	if field.Fields != nil {
+		return field.Fields
+	}
+	if md := messageDescriptions[field.StructName]; md != nil {
+		return md.Fields
+	}
+	return nil
+}
+
+// modelName returns the name of the model for the message,
+// the Go name is used to avoid collisions of the nested messages
+func modelName(fullName string) string {
+	if md := messageDescriptions[fullName]; md != nil && md.ProtogenMessage != nil {
+		return md.ProtogenMessage.GoIdent.GoName
+	}
+	return structName(fullName)
+}
+
+// modelType returns the Go type of the model for the message field
+func modelType(field *FieldMeta) string {
+	if typ, ok := wellKnownModelType(field.StructName); ok {
+		return typ
+	}
+	if modelFields(field) == nil {
+		return "json.RawMessage"
+	}
+	return "*" + modelName(field.StructName)
+}
+
 func structName(name string) string {
 	if i := strings.LastIndex(name, "."); i != -1 {
 		return name[i+1:]
@@ -238,39 +319,35 @@ func enumName(en *EnumDescription, opts Opts) string {
 func goModel(w io.Writer, opts Opts, m *MessageDescription) error {
 	fullName := values.StringsCoalesce(m.FullName, m.Name)
 	name := structName(m.Name)
+	if md := messageDescriptions[m.FullName]; md != nil && md.ProtogenMessage != nil {
+		name = md.ProtogenMessage.GoIdent.GoName
+	}
 
 	_, _ = fmt.Fprintf(w, "\n// %s is the Go model for the %s message.\n", name, fullName)
 	_, _ = fmt.Fprintln(w, "// It can be used to decode the message from JSON.")
 	_, _ = fmt.Fprintf(w, "type %s struct {\n", name)
 	for _, field := range m.Fields {
 		_, _ = fmt.Fprintf(w, "   %s ", field.GoName)
+		if field.Optional {
+			// the unset optional field is not decoded as the default value
+			_, _ = fmt.Fprint(w, "*")
+		}
 		switch field.Type {
 		case "struct", "object":
-			//fmt.Fprintf(w, "*%s", structName(field.Name))
-			_, _ = fmt.Fprintf(w, "json.RawMessage")
+			_, _ = fmt.Fprint(w, modelType(field))
 		case "[]struct":
-			//fmt.Fprintf(w, "[]*%s", structName(field.Name))
-			_, _ = fmt.Fprintf(w, "json.RawMessage")
+			_, _ = fmt.Fprintf(w, "[]%s", modelType(field))
 		case "map":
-			def := field.Fields
-			if def == nil {
-				md := messageDescriptions[field.StructName]
-				if md != nil {
-					def = md.Fields
-				}
-			}
+			def := modelFields(field)
 			if len(def) == 2 {
 				keyType := def[0].Type
 				valueType := def[1].Type
 				if valueType == "struct" {
-					// md := messageDescriptions[def[1].StructName]
-					// if md != nil {
-					// 	valueType = "*" + structName(def[1].Name)
-					// }
-					_, _ = fmt.Fprintf(w, "json.RawMessage")
-				} else {
-					_, _ = fmt.Fprintf(w, "map[%s]%s", keyType, valueType)
+					valueType = modelType(def[1])
+				} else if def[1].EnumDescription != nil {
+					valueType = enumName(def[1].EnumDescription, opts)
 				}
+				_, _ = fmt.Fprintf(w, "map[%s]%s", keyType, valueType)
 			} else {
 				_, _ = fmt.Fprintf(w, "json.RawMessage  // unable to find definition for %s", field.StructName)
 			}
@@ -290,7 +367,7 @@ func goModel(w io.Writer, opts Opts, m *MessageDescription) error {
 		default:
 			_, _ = fmt.Fprintf(w, "%s", field.Type)
 		}
-		_, _ = fmt.Fprintf(w, " `json:\"%s,omitempty\"`\n", field.Name)
+		_, _ = fmt.Fprintf(w, " `%s`\n", modelTags(field, opts))
 	}
 	_, _ = fmt.Fprintln(w, "}")
 	return nil
@@ -395,7 +472,7 @@ func GetEnumsDescriptions(gp *protogen.Plugin, opts Opts) []*EnumDescription {
 	return res
 }
 
-func GetMessagesDescriptions(gp *protogen.Plugin, opts Opts) []*MessageDescription {
+func GetMessagesDescriptions(gp *protogen.Plugin, opts Opts) ([]*MessageDescription, error) {
 	seen := make(map[string]*protogen.Message)
 	inputMap := make(map[string]bool)
 	outputMap := make(map[string]bool)
@@ -453,7 +530,10 @@ func GetMessagesDescriptions(gp *protogen.Plugin, opts Opts) []*MessageDescripti
 	msgsToDiscover := make(map[string]*protogen.Message)
 
 	for fn, msg := range seen {
-		desc := CreateMessageDescription(msg, inputMap[fn], outputMap[fn], opts, msgsToDiscover)
+		desc, err := CreateMessageDescription(msg, inputMap[fn], outputMap[fn], opts, msgsToDiscover)
+		if err != nil {
+			return nil, err
+		}
 		list = append(list, desc)
 	}
 
@@ -462,7 +542,10 @@ func GetMessagesDescriptions(gp *protogen.Plugin, opts Opts) []*MessageDescripti
 		prev := msgsToDiscover
 		msgsToDiscover = make(map[string]*protogen.Message)
 		for fn, msg := range prev {
-			desc := CreateMessageDescription(msg, inputMap[fn], outputMap[fn], opts, msgsToDiscover)
+			desc, err := CreateMessageDescription(msg, inputMap[fn], outputMap[fn], opts, msgsToDiscover)
+			if err != nil {
+				return nil, err
+			}
 			list = append(list, desc)
 			//logger.Infof("*** Discovered nested messages: %s", fn)
 		}
@@ -472,7 +555,7 @@ func GetMessagesDescriptions(gp *protogen.Plugin, opts Opts) []*MessageDescripti
 		return list[i].FullName < list[j].FullName
 	})
 
-	return list
+	return list, nil
 }
 
 func goName(importPath, name, thisPkg string) string {
@@ -512,6 +595,60 @@ func tempFuncs() template.FuncMap {
 		msg := md.ProtogenMessage
 		return "new(" + goName(string(msg.GoIdent.GoImportPath), msg.GoIdent.GoName, thisPkg) + ")"
 	}
+	numberBound := func(b *api.NumberBound) string {
+		var fn string
+		switch b.GetValue().(type) {
+		case *api.NumberBound_Int:
+			fn = "api.IntBound"
+		case *api.NumberBound_Uint:
+			fn = "api.UintBound"
+		case *api.NumberBound_Float:
+			fn = "api.FloatBound"
+		default:
+			return "nil"
+		}
+		return fmt.Sprintf("%s(%s, %t)", fn, b.Format(), b.Exclusive)
+	}
+	m["number_bound"] = numberBound
+	m["item_rules"] = func(r *api.ItemRules) string {
+		var fields []string
+		if r.Min != 0 {
+			fields = append(fields, fmt.Sprintf("Min: %d", r.Min))
+		}
+		if r.Max != 0 {
+			fields = append(fields, fmt.Sprintf("Max: %d", r.Max))
+		}
+		if r.Pattern != "" {
+			fields = append(fields, fmt.Sprintf("Pattern: %q", r.Pattern))
+		}
+		if r.Prefix != "" {
+			fields = append(fields, fmt.Sprintf("Prefix: %q", r.Prefix))
+		}
+		if r.Suffix != "" {
+			fields = append(fields, fmt.Sprintf("Suffix: %q", r.Suffix))
+		}
+		if r.Format != "" {
+			fields = append(fields, fmt.Sprintf("Format: %q", r.Format))
+		}
+		if len(r.In) > 0 {
+			fields = append(fields, fmt.Sprintf("In: %#v", r.In))
+		}
+		if len(r.NotIn) > 0 {
+			fields = append(fields, fmt.Sprintf("NotIn: %#v", r.NotIn))
+		}
+		if r.Range != nil {
+			var bounds []string
+			if r.Range.Min != nil {
+				bounds = append(bounds, "Min: "+numberBound(r.Range.Min))
+			}
+			if r.Range.Max != nil {
+				bounds = append(bounds, "Max: "+numberBound(r.Range.Max))
+			}
+			fields = append(fields, "Range: &api.NumberRange{"+strings.Join(bounds, ", ")+"}")
+		}
+		return "&api.ItemRules{" + strings.Join(fields, ", ") + "}"
+	}
+	m["static_validators"] = staticValidators
 	m["trim_package"] = TrimLocalPackageName
 	m["package_name"] = ExternalPackageName
 	m["supported"] = func(f *protogen.Enum) string {
@@ -601,8 +738,9 @@ type tplMessage struct {
 }
 
 type tplMessagesMap struct {
-	Package      string
-	Descriptions []*MessageDescription
+	Package        string
+	StaticValidate bool
+	Descriptions   []*MessageDescription
 }
 
 var (
@@ -616,12 +754,21 @@ var (
 package {{.Package}}
 
 import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"github.com/effective-security/x/enum"
 	"github.com/effective-security/protoc-gen-go/api"
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 `))
 
@@ -892,6 +1039,34 @@ var {{.Description.Name}}_MessageDescription = &api.MessageDescription {
 	{{- if .Description.Deprecated }}
 	Deprecated: true,
 	{{- end }}
+	{{- if .Description.Rules }}
+	Rules: []*api.MessageRule{
+	{{- range .Description.Rules }}
+		{
+			{{- if .ID }}
+			ID: {{printf "%q" .ID}},
+			{{- end }}
+			Expr: {{printf "%q" .Expr}},
+			{{- if .Message }}
+			Message: {{printf "%q" .Message}},
+			{{- end }}
+		},
+	{{- end }}
+	},
+	{{- end }}
+	{{- if .Description.Oneofs }}
+	Oneofs: []*api.OneofMeta{
+	{{- range .Description.Oneofs }}
+		{
+			Name: "{{.Name}}",
+			Fields: []string{ {{- range $i, $f := .Fields }}{{if $i}}, {{end}}"{{$f}}"{{- end }} },
+			{{- if .Required }}
+			Required: true,
+			{{- end }}
+		},
+	{{- end }}
+	},
+	{{- end }}
 	Fields: []*api.FieldMeta {
 	{{- range .Description.Fields }}
 		{
@@ -937,6 +1112,80 @@ var {{.Description.Name}}_MessageDescription = &api.MessageDescription {
 			{{- if .MaxCount }}
 			MaxCount: {{.MaxCount}},
 			{{- end }}
+			{{- if .Pattern }}
+			Pattern: {{printf "%q" .Pattern}},
+			{{- end }}
+			{{- if .Prefix }}
+			Prefix: {{printf "%q" .Prefix}},
+			{{- end }}
+			{{- if .Suffix }}
+			Suffix: {{printf "%q" .Suffix}},
+			{{- end }}
+			{{- if .Format }}
+			Format: "{{.Format}}",
+			{{- end }}
+			{{- if .In }}
+			In: {{list .In}},
+			{{- end }}
+			{{- if .NotIn }}
+			NotIn: {{list .NotIn}},
+			{{- end }}
+			{{- if .Range }}
+			Range: &api.NumberRange{
+				{{- if .Range.Min }}
+				Min: {{number_bound .Range.Min}},
+				{{- end }}
+				{{- if .Range.Max }}
+				Max: {{number_bound .Range.Max}},
+				{{- end }}
+			},
+			{{- end }}
+			{{- if .TimeRange }}
+			TimeRange: &api.TimeRange{
+				{{- if .TimeRange.LtNow }}
+				LtNow: true,
+				{{- end }}
+				{{- if .TimeRange.GtNow }}
+				GtNow: true,
+				{{- end }}
+				{{- if .TimeRange.NotBeforeNow }}
+				NotBeforeNow: true,
+				{{- end }}
+				{{- if .TimeRange.Within }}
+				Within: "{{.TimeRange.Within}}",
+				{{- end }}
+				{{- if .TimeRange.MinDuration }}
+				MinDuration: "{{.TimeRange.MinDuration}}",
+				{{- end }}
+				{{- if .TimeRange.MaxDuration }}
+				MaxDuration: "{{.TimeRange.MaxDuration}}",
+				{{- end }}
+			},
+			{{- end }}
+			{{- if .Unique }}
+			Unique: true,
+			{{- end }}
+			{{- if .Items }}
+			Items: {{item_rules .Items}},
+			{{- end }}
+			{{- if .Keys }}
+			Keys: {{item_rules .Keys}},
+			{{- end }}
+			{{- if .Sensitive }}
+			Sensitive: true,
+			{{- end }}
+			{{- if .RevealRoles }}
+			RevealRoles: {{list .RevealRoles}},
+			{{- end }}
+			{{- if .OutputOnly }}
+			OutputOnly: true,
+			{{- end }}
+			{{- if .InputOnly }}
+			InputOnly: true,
+			{{- end }}
+			{{- if .Immutable }}
+			Immutable: true,
+			{{- end }}
 			{{- if .Deprecated }}
 			Deprecated: true,
 			{{- end }}
@@ -979,6 +1228,10 @@ var (
 	}
 )
 
+{{- if .StaticValidate }}
+
+{{ static_validators .Descriptions .Package }}
+{{- else }}
 {{- range .Descriptions }}
 {{- if and .IsInput (eq .Package $root.Package) }}
 func (m *{{.Name}}) Validate(ctx context.Context) error {
@@ -986,6 +1239,7 @@ func (m *{{.Name}}) Validate(ctx context.Context) error {
 }
 {{- end }}
 {{- end }}
+{{- end }}
 
 {{- range .Descriptions }}
 {{- if and .IsOutput (eq .Package $root.Package) }}
@@ -1026,6 +1280,11 @@ func GetMessageDescription(fullname string) *api.MessageDescription {
 
 func init() {
     _ = GetMessageDescriptions()
+{{- range .Descriptions }}
+{{- if and .Rules (eq .Package $root.Package) }}
+	api.MustCompileMessageRules({{.Name}}_MessageDescription, (*{{.Name}})(nil))
+{{- end }}
+{{- end }}
 }
 `))
 )
@@ -2,13 +2,18 @@ package enumgen
 
 import (
 	"path"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/cockroachdb/errors"
 	"github.com/effective-security/protoc-gen-go/api"
 	"github.com/effective-security/x/format"
 	"github.com/effective-security/x/slices"
+	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
@@ -78,11 +83,12 @@ func CreateEnumDescription(en *protogen.Enum) *EnumDescription {
 	return res
 }
 
-// CreateMessageDescription convert enum descriptor to EnumMeta message
-func CreateMessageDescription(msg *protogen.Message, isInput, isOutput bool, args Opts, queueToDiscover map[string]*protogen.Message) *MessageDescription {
+// CreateMessageDescription convert message descriptor to MessageDescription,
+// or returns an error if the message or field options are invalid
+func CreateMessageDescription(msg *protogen.Message, isInput, isOutput bool, args Opts, queueToDiscover map[string]*protogen.Message) (*MessageDescription, error) {
 	fn := string(msg.Desc.FullName())
 	if _, ok := messageDescriptions[fn]; ok {
-		return messageDescriptions[fn]
+		return messageDescriptions[fn], nil
 	}
 
 	opts := msg.Desc.Options().ProtoReflect()
@@ -90,6 +96,11 @@ func CreateMessageDescription(msg *protogen.Message, isInput, isOutput bool, arg
 	display := opts.Get(api.E_MessageDisplay.TypeDescriptor()).String()
 	description := opts.Get(api.E_MessageDescription.TypeDescriptor()).String()
 	generateModel := opts.Get(api.E_GenerateModel.TypeDescriptor()).Bool()
+	rules, _ := proto.GetExtension(msg.Desc.Options(), api.E_Rules).([]*api.MessageRule)
+	// fail the generation, instead of failing the validation at runtime
+	if err := api.CompileMessageRules(msg.Desc, rules); err != nil {
+		return nil, errors.Errorf("invalid es.api.rules of %s: %s", fn, err.Error())
+	}
 	deprecated := false
 	ro := msg.Desc.Options()
 	if mo, ok := ro.(*descriptorpb.MethodOptions); ok {
@@ -113,6 +124,7 @@ func CreateMessageDescription(msg *protogen.Message, isInput, isOutput bool, arg
 		IsInput:       isInput,
 		IsOutput:      isOutput,
 		GenerateModel: generateModel,
+		Rules:         rules,
 
 		ProtogenMessage: msg,
 		Package:         path.Base(string(msg.GoIdent.GoImportPath)),
@@ -121,16 +133,35 @@ func CreateMessageDescription(msg *protogen.Message, isInput, isOutput bool, arg
 		res.Display = display
 	}
 
+	for _, oneof := range msg.Oneofs {
+		// proto3 optional fields are wrapped in synthetic oneofs
+		if oneof.Desc.IsSynthetic() {
+			continue
+		}
+		om := &api.OneofMeta{
+			Name:     string(oneof.Desc.Name()),
+			Required: proto.GetExtension(oneof.Desc.Options(), api.E_OneofRequired).(bool),
+		}
+		for _, field := range oneof.Fields {
+			om.Fields = append(om.Fields, string(field.Desc.Name()))
+		}
+		res.Oneofs = append(res.Oneofs, om)
+	}
+
 	for _, field := range msg.Fields {
-		res.Fields = append(res.Fields, fieldMeta(field, args, queueToDiscover))
+		fm, err := fieldMeta(field, args, queueToDiscover)
+		if err != nil {
+			return nil, err
+		}
+		res.Fields = append(res.Fields, fm)
 	}
 
 	messageDescriptions[fn] = res
 	delete(queueToDiscover, fn)
-	return res
+	return res, nil
 }
 
-func fieldMeta(field *protogen.Field, args Opts, queueToDiscover map[string]*protogen.Message) *FieldMeta {
+func fieldMeta(field *protogen.Field, args Opts, queueToDiscover map[string]*protogen.Message) (*FieldMeta, error) {
 	opts := field.Desc.Options().ProtoReflect()
 
 	alias := opts.Get(api.E_Alias.TypeDescriptor()).String()
@@ -143,6 +174,83 @@ func fieldMeta(field *protogen.Field, args Opts, queueToDiscover map[string]*pro
 	max := opts.Get(api.E_Max.TypeDescriptor()).Int()
 	minCount := opts.Get(api.E_MinCount.TypeDescriptor()).Int()
 	maxCount := opts.Get(api.E_MaxCount.TypeDescriptor()).Int()
+	pattern := opts.Get(api.E_Pattern.TypeDescriptor()).String()
+	prefix := opts.Get(api.E_Prefix.TypeDescriptor()).String()
+	suffix := opts.Get(api.E_Suffix.TypeDescriptor()).String()
+	strFormat := opts.Get(api.E_Format.TypeDescriptor()).String()
+	in := opts.Get(api.E_In.TypeDescriptor()).String()
+	notIn := opts.Get(api.E_NotIn.TypeDescriptor()).String()
+	var numRange *api.NumberRange
+	if proto.HasExtension(field.Desc.Options(), api.E_Range) {
+		numRange = proto.GetExtension(field.Desc.Options(), api.E_Range).(*api.NumberRange)
+	}
+	var timeRange *api.TimeRange
+	if proto.HasExtension(field.Desc.Options(), api.E_TimeRange) {
+		timeRange = proto.GetExtension(field.Desc.Options(), api.E_TimeRange).(*api.TimeRange)
+		for _, d := range []string{timeRange.Within, timeRange.MinDuration, timeRange.MaxDuration} {
+			if d == "" {
+				continue
+			}
+			if _, err := time.ParseDuration(d); err != nil {
+				return nil, errors.Errorf("invalid es.api.time_range of %s: %s", field.Desc.FullName(), err.Error())
+			}
+		}
+	}
+	unique := opts.Get(api.E_Unique.TypeDescriptor()).Bool()
+	sensitive := opts.Get(api.E_Sensitive.TypeDescriptor()).Bool()
+	revealRoles := opts.Get(api.E_RevealRoles.TypeDescriptor()).String()
+	modelTags := opts.Get(api.E_ModelTags.TypeDescriptor()).String()
+	if _, err := parseStructTags(modelTags); err != nil {
+		return nil, errors.Errorf("invalid es.api.model_tags of %s: %s", field.Desc.FullName(), err.Error())
+	}
+	// google.api.field_behavior, REQUIRED is the same as es.api.required
+	if api.HasFieldBehavior(field.Desc, annotations.FieldBehavior_REQUIRED) {
+		required = true
+	}
+	outputOnly := api.HasFieldBehavior(field.Desc, annotations.FieldBehavior_OUTPUT_ONLY)
+	inputOnly := api.HasFieldBehavior(field.Desc, annotations.FieldBehavior_INPUT_ONLY)
+	immutable := api.HasFieldBehavior(field.Desc, annotations.FieldBehavior_IMMUTABLE)
+	var items, keys *api.ItemRules
+	if proto.HasExtension(field.Desc.Options(), api.E_Items) {
+		items = proto.GetExtension(field.Desc.Options(), api.E_Items).(*api.ItemRules)
+	}
+	if proto.HasExtension(field.Desc.Options(), api.E_Keys) {
+		keys = proto.GetExtension(field.Desc.Options(), api.E_Keys).(*api.ItemRules)
+	}
+	// fail the generation, instead of failing the validation at runtime
+	if err := checkStringRules(field, "pattern", pattern, "format", strFormat); err != nil {
+		return nil, err
+	}
+	if field.Desc.IsList() {
+		if opt := valueOption(min, max, pattern, prefix, suffix, strFormat, in, notIn, numRange, timeRange); opt != "" {
+			return nil, errors.Errorf("es.api.%s of %s: use es.api.items for repeated fields", opt, field.Desc.FullName())
+		}
+	}
+	if items != nil {
+		if !field.Desc.IsList() {
+			return nil, errors.Errorf("es.api.items of %s: must be used with repeated fields", field.Desc.FullName())
+		}
+		if err := checkStringRules(field, "items.Pattern", items.Pattern, "items.Format", items.Format); err != nil {
+			return nil, err
+		}
+	}
+	if keys != nil {
+		if !field.Desc.IsMap() {
+			return nil, errors.Errorf("es.api.keys of %s: must be used with map fields", field.Desc.FullName())
+		}
+		if err := checkStringRules(field, "keys.Pattern", keys.Pattern, "keys.Format", keys.Format); err != nil {
+			return nil, err
+		}
+	}
+	if unique && (!field.Desc.IsList() || field.Desc.Kind() == protoreflect.MessageKind) {
+		return nil, errors.Errorf("es.api.unique of %s: must be used with repeated scalar or enum fields", field.Desc.FullName())
+	}
+	if outputOnly && inputOnly {
+		return nil, errors.Errorf("google.api.field_behavior of %s: OUTPUT_ONLY and INPUT_ONLY are mutually exclusive", field.Desc.FullName())
+	}
+	if revealRoles != "" && !sensitive {
+		return nil, errors.Errorf("es.api.reveal_roles of %s: must be used with es.api.sensitive", field.Desc.FullName())
+	}
 	deprecated := false
 	if fo, ok := field.Desc.Options().(*descriptorpb.FieldOptions); ok {
 		deprecated = fo.GetDeprecated()
@@ -188,6 +296,23 @@ func fieldMeta(field *protogen.Field, args Opts, queueToDiscover map[string]*pro
 		Max:           int32(max),
 		MinCount:      int32(minCount),
 		MaxCount:      int32(maxCount),
+		Pattern:       pattern,
+		Prefix:        prefix,
+		Suffix:        suffix,
+		Format:        strFormat,
+		In:            slices.StringsSafeSplit(in, ","),
+		NotIn:         slices.StringsSafeSplit(notIn, ","),
+		Range:         numRange,
+		TimeRange:     timeRange,
+		Unique:        unique,
+		Items:         items,
+		Keys:          keys,
+		Sensitive:     sensitive,
+		RevealRoles:   slices.StringsSafeSplit(revealRoles, ","),
+		ModelTags:     modelTags,
+		OutputOnly:    outputOnly,
+		InputOnly:     inputOnly,
+		Immutable:     immutable,
 		Deprecated:    deprecated,
 
 		ProtogenField: field,
@@ -201,6 +326,10 @@ func fieldMeta(field *protogen.Field, args Opts, queueToDiscover map[string]*pro
 	kind := field.Desc.Kind()
 	isList := field.Desc.IsList()
 	isMap := field.Desc.IsMap()
+	// proto3 optional, oneof members and editions fields with explicit presence,
+	// the nil bytes are not set, so the bytes are not pointers in the models
+	fm.Optional = field.Desc.HasPresence() && kind != protoreflect.BytesKind &&
+		kind != protoreflect.MessageKind && kind != protoreflect.GroupKind
 
 	goType, _ := mapScalarToTypes(kind)
 	//fm.GoType = goTyp
@@ -232,7 +361,48 @@ func fieldMeta(field *protogen.Field, args Opts, queueToDiscover map[string]*pro
 	if isList {
 		fm.Type = "[]" + goType
 	}
-	return fm
+	return fm, nil
+}
+
+// valueOption returns the name of the first value option that is set,
+// or empty string
+func valueOption(min, max int64, pattern, prefix, suffix, strFormat, in, notIn string, numRange *api.NumberRange, timeRange *api.TimeRange) string {
+	switch {
+	case min != 0:
+		return "min"
+	case max != 0:
+		return "max"
+	case pattern != "":
+		return "pattern"
+	case prefix != "":
+		return "prefix"
+	case suffix != "":
+		return "suffix"
+	case strFormat != "":
+		return "format"
+	case in != "":
+		return "in"
+	case notIn != "":
+		return "not_in"
+	case numRange != nil:
+		return "range"
+	case timeRange != nil:
+		return "time_range"
+	}
+	return ""
+}
+
+// checkStringRules returns an error if the pattern or the format option is invalid
+func checkStringRules(field *protogen.Field, patternOpt, pattern, formatOpt, strFormat string) error {
+	if pattern != "" {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return errors.Errorf("invalid es.api.%s of %s: %s", patternOpt, field.Desc.FullName(), err.Error())
+		}
+	}
+	if strFormat != "" && !api.IsStringFormat(strFormat) {
+		return errors.Errorf("unsupported es.api.%s of %s: %s", formatOpt, field.Desc.FullName(), strFormat)
+	}
+	return nil
 }
 
 func cleanComment(comment string) string {
@@ -328,6 +498,10 @@ type MessageDescription struct {
 	IsOutput bool
 
 	GenerateModel bool
+	// Rules are the message-level validation rules
+	Rules []*api.MessageRule
+	// Oneofs are the oneof groups of the message
+	Oneofs []*api.OneofMeta
 
 	// message is the original message descriptor
 	ProtogenMessage *protogen.Message
@@ -352,8 +526,27 @@ type FieldMeta struct {
 	Max             int32
 	MinCount        int32
 	MaxCount        int32
+	Pattern         string
+	Prefix          string
+	Suffix          string
+	Format          string
+	In              []string
+	NotIn           []string
+	Range           *api.NumberRange
+	TimeRange       *api.TimeRange
+	Unique          bool
+	Items           *api.ItemRules
+	Keys            *api.ItemRules
+	Sensitive       bool
+	RevealRoles     []string
+	OutputOnly      bool
+	InputOnly       bool
+	Immutable       bool
+	Optional        bool
 	Deprecated      bool
 	Alias           string
+	// ModelTags are the additional struct tags of the field in the models
+	ModelTags string
 
 	// field is the original field descriptor
 	ProtogenField         *protogen.Field
@@ -2,6 +2,7 @@ package enumgen
 
 import (
 	"bytes"
+	goformat "go/format"
 	"os"
 	"testing"
 
@@ -10,8 +11,11 @@ import (
 	"github.com/effective-security/x/format"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
 	pluginpb "google.golang.org/protobuf/types/pluginpb"
 )
 
@@ -90,10 +94,171 @@ func Test_CreateMessageDescription(t *testing.T) {
 
 	ops := Opts{Package: "e2e"}
 
-	descriptions := GetMessagesDescriptions(p, ops)
+	descriptions, err := GetMessagesDescriptions(p, ops)
+	require.NoError(t, err)
 	assert.Equal(t, 27, len(descriptions))
 }
 
+func Test_CreateMessageDescription_InvalidOptions(t *testing.T) {
+	strField := func(name string, label descriptorpb.FieldDescriptorProto_Label, set func(*descriptorpb.FieldOptions)) *descriptorpb.FieldDescriptorProto {
+		opts := &descriptorpb.FieldOptions{}
+		set(opts)
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(1),
+			Label:    label.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			Options:  opts,
+		}
+	}
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+
+	tests := []struct {
+		name   string
+		field  *descriptorpb.FieldDescriptorProto
+		rules  []*api.MessageRule
+		expErr string
+	}{
+		{
+			name:   "rules",
+			field:  strField("Name", optional, func(*descriptorpb.FieldOptions) {}),
+			rules:  []*api.MessageRule{{Expr: "this.Unknown == 1"}},
+			expErr: "invalid es.api.rules of test.Test:",
+		},
+		{
+			name: "time_range",
+			field: strField("Name", optional, func(o *descriptorpb.FieldOptions) {
+				proto.SetExtension(o, api.E_TimeRange, &api.TimeRange{Within: "1x"})
+			}),
+			expErr: "invalid es.api.time_range of test.Test.Name:",
+		},
+		{
+			name: "model_tags",
+			field: strField("Name", optional, func(o *descriptorpb.FieldOptions) {
+				proto.SetExtension(o, api.E_ModelTags, "db:name")
+			}),
+			expErr: "invalid es.api.model_tags of test.Test.Name:",
+		},
+		{
+			name: "pattern",
+			field: strField("Name", optional, func(o *descriptorpb.FieldOptions) {
+				proto.SetExtension(o, api.E_Pattern, "[a-z")
+			}),
+			expErr: "invalid es.api.pattern of test.Test.Name:",
+		},
+		{
+			name: "format",
+			field: strField("Name", optional, func(o *descriptorpb.FieldOptions) {
+				proto.SetExtension(o, api.E_Format, "phone")
+			}),
+			expErr: "unsupported es.api.format of test.Test.Name: phone",
+		},
+		{
+			name: "items",
+			field: strField("Name", optional, func(o *descriptorpb.FieldOptions) {
+				proto.SetExtension(o, api.E_Items, &api.ItemRules{Min: 1})
+			}),
+			expErr: "es.api.items of test.Test.Name: must be used with repeated fields",
+		},
+		{
+			name: "items_format",
+			field: strField("Names", repeated, func(o *descriptorpb.FieldOptions) {
+				proto.SetExtension(o, api.E_Items, &api.ItemRules{Format: "phone"})
+			}),
+			expErr: "unsupported es.api.items.Format of test.Test.Names: phone",
+		},
+		{
+			name: "repeated",
+			field: strField("Names", repeated, func(o *descriptorpb.FieldOptions) {
+				proto.SetExtension(o, api.E_Max, int32(64))
+			}),
+			expErr: "es.api.max of test.Test.Names: use es.api.items for repeated fields",
+		},
+		{
+			name: "keys",
+			field: strField("Names", repeated, func(o *descriptorpb.FieldOptions) {
+				proto.SetExtension(o, api.E_Keys, &api.ItemRules{Min: 1})
+			}),
+			expErr: "es.api.keys of test.Test.Names: must be used with map fields",
+		},
+		{
+			name: "unique",
+			field: strField("Name", optional, func(o *descriptorpb.FieldOptions) {
+				proto.SetExtension(o, api.E_Unique, true)
+			}),
+			expErr: "es.api.unique of test.Test.Name: must be used with repeated scalar or enum fields",
+		},
+		{
+			name: "field_behavior",
+			field: strField("Name", optional, func(o *descriptorpb.FieldOptions) {
+				proto.SetExtension(o, annotations.E_FieldBehavior, []annotations.FieldBehavior{
+					annotations.FieldBehavior_OUTPUT_ONLY,
+					annotations.FieldBehavior_INPUT_ONLY,
+				})
+			}),
+			expErr: "google.api.field_behavior of test.Test.Name: OUTPUT_ONLY and INPUT_ONLY are mutually exclusive",
+		},
+		{
+			name: "reveal_roles",
+			field: strField("Name", optional, func(o *descriptorpb.FieldOptions) {
+				proto.SetExtension(o, api.E_RevealRoles, "admin")
+			}),
+			expErr: "es.api.reveal_roles of test.Test.Name: must be used with es.api.sensitive",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			saved := messageDescriptions
+			messageDescriptions = make(map[string]*MessageDescription)
+			defer func() { messageDescriptions = saved }()
+
+			msgOpts := &descriptorpb.MessageOptions{}
+			proto.SetExtension(msgOpts, api.E_GenerateMeta, true)
+			if tc.rules != nil {
+				proto.SetExtension(msgOpts, api.E_Rules, tc.rules)
+			}
+			p := newTestPlugin(t, &descriptorpb.DescriptorProto{
+				Name:    proto.String("Test"),
+				Field:   []*descriptorpb.FieldDescriptorProto{tc.field},
+				Options: msgOpts,
+			})
+
+			_, err := GetMessagesDescriptions(p, Opts{Package: "test"})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expErr)
+		})
+	}
+}
+
+// newTestPlugin returns the plugin to generate test.proto with the message
+func newTestPlugin(t *testing.T, msg *descriptorpb.DescriptorProto) *protogen.Plugin {
+	t.Helper()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:        proto.String("test.proto"),
+		Package:     proto.String("test"),
+		Syntax:      proto.String("proto3"),
+		Dependency:  []string{"annotations.proto", "google/api/field_behavior.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{msg},
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("example.com/test;test"),
+		},
+	}
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"test.proto"},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(api.File_annotations_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_field_behavior_proto),
+			file,
+		},
+	}
+	p, err := protogen.Options{}.New(req)
+	require.NoError(t, err)
+	return p
+}
+
 func loadPluginFromRequestBin(t *testing.T, path string) *protogen.Plugin {
 	t.Helper()
 	data, err := os.ReadFile(path)
@@ -107,6 +272,11 @@ func loadPluginFromRequestBin(t *testing.T, path string) *protogen.Plugin {
 }
 
 func Test_GoModel(t *testing.T) {
+	// the models of the loaded messages have the converters
+	saved := messageDescriptions
+	messageDescriptions = make(map[string]*MessageDescription)
+	defer func() { messageDescriptions = saved }()
+
 	hestedFields := []*FieldMeta{
 		{
 			Name:   "NestedField",
@@ -153,11 +323,17 @@ func Test_GoModel(t *testing.T) {
 				Type:          "int32",
 				SearchOptions: api.SearchOption_None,
 			},
+			{
+				Name:     "Priority",
+				GoName:   "Priority",
+				Type:     "uint32",
+				Optional: true,
+			},
 			{
 				Name:       "Nested",
 				GoName:     "Nested",
 				Type:       "struct",
-				StructName: "Nested",
+				StructName: "e2e.Nested",
 				Package:    "e2e",
 				Fields:     hestedFields,
 			},
@@ -165,12 +341,37 @@ func Test_GoModel(t *testing.T) {
 				Name:       "NestedList",
 				GoName:     "NestedList",
 				Type:       "[]struct",
-				StructName: "Nested",
+				StructName: "e2e.Nested",
 				Package:    "e2e",
 				Fields:     hestedFields,
 			},
+			{
+				Name:   "NestedMap",
+				GoName: "NestedMap",
+				Type:   "map",
+				Fields: []*FieldMeta{
+					{Name: "key", GoName: "Key", Type: "string"},
+					{Name: "value", GoName: "Value", Type: "struct", StructName: "e2e.Nested", Fields: hestedFields},
+				},
+			},
+			{Name: "created", GoName: "Created", Type: "struct", StructName: "google.protobuf.Timestamp"},
+			{Name: "timeout", GoName: "Timeout", Type: "struct", StructName: "google.protobuf.Duration"},
+			{Name: "labels", GoName: "Labels", Type: "struct", StructName: "google.protobuf.Struct"},
+			{Name: "count", GoName: "Count", Type: "struct", StructName: "google.protobuf.Int64Value"},
+			{Name: "any", GoName: "Any", Type: "struct", StructName: "google.protobuf.Any"},
+			{Name: "unknown", GoName: "Unknown", Type: "struct", StructName: "e2e.Unknown"},
 		},
 	}
+	// the cycle is generated once
+	hestedFields = append(hestedFields, &FieldMeta{
+		Name:       "Parent",
+		GoName:     "Parent",
+		Type:       "struct",
+		StructName: "e2e.Test",
+		Fields:     msg.Fields,
+	})
+	msg.Fields[4].Fields = hestedFields
+	msg.Fields[5].Fields = hestedFields
 
 	opts := Opts{Package: "e2e", ModelPackage: "modelpb"}
 
@@ -183,3 +384,225 @@ func Test_GoModel(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, string(exp), js)
 }
+
+func Test_staticValidator(t *testing.T) {
+	p := loadPluginFromRequestBin(t, "testdata/code_generator_request.pb.bin")
+	_, err := GetMessagesDescriptions(p, Opts{Package: "e2e"})
+	require.NoError(t, err)
+
+	basic := messageDescriptions["e2e.Basic"]
+	require.NotNil(t, basic)
+	basic = &MessageDescription{
+		Name:            basic.Name,
+		FullName:        basic.FullName,
+		Fields:          basic.Fields,
+		ProtogenMessage: basic.ProtogenMessage,
+		Package:         basic.Package,
+		IsInput:         true,
+	}
+	basic.Oneofs = []*api.OneofMeta{{Name: "b", Fields: []string{"int", "str", "id"}, Required: true}}
+
+	code := staticValidators([]*MessageDescription{basic}, basic.Package)
+	assert.Contains(t, code, "return api.ValidateStatic(ctx, m, Basic_MessageDescription, validateBasic)")
+	assert.Contains(t, code, "if m.B == nil {\nif err := v.Oneof(Basic_MessageDescription.Oneofs[0]); err != nil {")
+	// direct checks, without protoreflect
+	assert.NotContains(t, code, "protoreflect")
+	assert.Contains(t, code, "if len(m.GetName()) < 8 {\nif err := v.MinLength(fields[8], \"name\", len(m.GetName())); err != nil {")
+	assert.Contains(t, code, "if invalid := int32(m.GetStatuses()) &^ 2147483647; invalid != 0 {\nif err := v.EnumFlags(fields[6], \"statuses\", int32(m.GetStatuses()), invalid);")
+
+	assert.Equal(t, `"a["+strconv.Itoa(i)+"]."`, concat(`"a"`, `"["`, "strconv.Itoa(i)", `"]"`, `"."`))
+	assert.Equal(t, `prefix+"a.keys["+key+"]"`, concat(`prefix+"a"`, `".keys["`, "key", `"]"`))
+
+	assert.Equal(t, `m.GetName() == ""`, negate(`m.GetName() != ""`))
+	assert.Equal(t, "len(m.GetValues()) == 0", negate("len(m.GetValues()) > 0"))
+	assert.Equal(t, "m.Created == nil", negate("m.Created != nil"))
+	assert.Equal(t, "!hasStr", negate("hasStr"))
+	assert.Equal(t, `!(m.GetA() != "" || hasStr)`, negate(`m.GetA() != "" || hasStr`))
+	assert.Equal(t, "true", negate("false"))
+}
+
+func Test_staticValidators_Nested(t *testing.T) {
+	saved := messageDescriptions
+	messageDescriptions = make(map[string]*MessageDescription)
+	defer func() { messageDescriptions = saved }()
+
+	nameOpts := &descriptorpb.FieldOptions{}
+	proto.SetExtension(nameOpts, api.E_Min, int32(3))
+	proto.SetExtension(nameOpts, api.E_Pattern, "^[a-z]+$")
+	countOpts := &descriptorpb.FieldOptions{}
+	proto.SetExtension(countOpts, api.E_Range, &api.NumberRange{Min: api.IntBound(1, false), Max: api.FloatBound(2.5, true)})
+	msgOpts := &descriptorpb.MessageOptions{}
+	proto.SetExtension(msgOpts, api.E_GenerateMeta, true)
+
+	p := newTestPlugin(t, &descriptorpb.DescriptorProto{
+		Name: proto.String("Outer"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     proto.String("inner"),
+				Number:   proto.Int32(1),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: proto.String(".test.Outer.Inner"),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				JsonName: proto.String("inner"),
+			},
+			{
+				Name:     proto.String("items"),
+				Number:   proto.Int32(2),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: proto.String(".test.Outer.Inner"),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				JsonName: proto.String("items"),
+			},
+			{
+				Name:     proto.String("count"),
+				Number:   proto.Int32(3),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				JsonName: proto.String("count"),
+				Options:  countOpts,
+			},
+		},
+		NestedType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Inner"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("name"),
+						Number:   proto.Int32(1),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						JsonName: proto.String("name"),
+						Options:  nameOpts,
+					},
+				},
+			},
+		},
+		Options: msgOpts,
+	})
+
+	msgs, err := GetMessagesDescriptions(p, Opts{Package: "test"})
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	msgs[0].IsInput = true
+
+	code := staticValidators(msgs, "test")
+	assert.NotContains(t, code, "protoreflect")
+	assert.Contains(t, code, "patternOuter_InnerName = regexp.MustCompile(\"^[a-z]+$\")")
+	// the nested message is validated by the generated function
+	assert.Contains(t, code, "func validateOuter_InnerFields(v *api.Validation, m *Outer_Inner, prefix string) error {")
+	assert.Contains(t, code, "if len(fields[0].Fields) > 0 && m.GetInner() != nil {\nif err := validateOuter_InnerFields(v, m.GetInner(), \"inner.\"); err != nil {")
+	assert.Contains(t, code, "if len(fields[1].Fields) > 0 {\nfor i, item := range m.GetItems() {\nif err := validateOuter_InnerFields(v, item, \"items[\"+strconv.Itoa(i)+\"].\"); err != nil {")
+	assert.Contains(t, code, "if len(m.GetName()) < 3 {\nif err := v.MinLength(fields[0], prefix+\"name\", len(m.GetName())); err != nil {")
+	assert.Contains(t, code, "if !patternOuter_InnerName.MatchString(m.GetName()) {\nif err := v.Pattern(fields[0], prefix+\"name\"); err != nil {")
+	// the range bounds are compared with the widened value
+	assert.Contains(t, code, "if int64(m.GetCount()) < 1 {\nif err := v.RangeMin(fields[2], \"count\", m.GetCount()); err != nil {")
+	assert.Contains(t, code, "if !(float64(int64(m.GetCount())) < 2.5) {\nif err := v.RangeMax(fields[2], \"count\", m.GetCount()); err != nil {")
+
+	// the nested message without constraints is not validated
+	msgs[1].Fields = nil
+	code = staticValidators(msgs, "test")
+	assert.NotContains(t, code, "validateOuter_InnerFields")
+}
+
+func Test_staticValidators_Golden(t *testing.T) {
+	saved := messageDescriptions
+	messageDescriptions = make(map[string]*MessageDescription)
+	defer func() { messageDescriptions = saved }()
+
+	fieldOpts := func(set func(opts *descriptorpb.FieldOptions)) *descriptorpb.FieldOptions {
+		opts := &descriptorpb.FieldOptions{}
+		set(opts)
+		return opts
+	}
+	field := func(name string, num int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, opts *descriptorpb.FieldOptions) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(num),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+			JsonName: proto.String(name),
+			Options:  opts,
+		}
+	}
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING
+
+	msgOpts := &descriptorpb.MessageOptions{}
+	proto.SetExtension(msgOpts, api.E_GenerateMeta, true)
+	p := newTestPlugin(t, &descriptorpb.DescriptorProto{
+		Name: proto.String("Request"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			// required without other checks
+			field("code", 1, str, optional, fieldOpts(func(opts *descriptorpb.FieldOptions) {
+				proto.SetExtension(opts, api.E_Required, true)
+			})),
+			// required with the checks of the value
+			field("name", 2, str, optional, fieldOpts(func(opts *descriptorpb.FieldOptions) {
+				proto.SetExtension(opts, api.E_Required, true)
+				proto.SetExtension(opts, api.E_Min, int32(3))
+				proto.SetExtension(opts, api.E_Prefix, "n")
+			})),
+			// required_or with the checks, that fail for the empty value
+			field("asset_id", 3, str, optional, fieldOpts(func(opts *descriptorpb.FieldOptions) {
+				proto.SetExtension(opts, api.E_RequiredOr, "resource_id")
+				proto.SetExtension(opts, api.E_Min, int32(2))
+				proto.SetExtension(opts, api.E_Max, int32(19))
+				proto.SetExtension(opts, api.E_Pattern, "^[a-z]+$")
+				proto.SetExtension(opts, api.E_In, "ab,cd")
+			})),
+			// required_or with the checks, that can not fail for the empty value
+			field("resource_id", 4, str, optional, fieldOpts(func(opts *descriptorpb.FieldOptions) {
+				proto.SetExtension(opts, api.E_RequiredOr, "asset_id")
+				proto.SetExtension(opts, api.E_Max, int32(19))
+				proto.SetExtension(opts, api.E_Format, "uuid")
+			})),
+			field("tags", 5, str, repeated, fieldOpts(func(opts *descriptorpb.FieldOptions) {
+				proto.SetExtension(opts, api.E_RequiredOr, "asset_id")
+				proto.SetExtension(opts, api.E_MinCount, int32(1))
+				proto.SetExtension(opts, api.E_MaxCount, int32(3))
+			})),
+		},
+		Options: msgOpts,
+	})
+
+	msgs, err := GetMessagesDescriptions(p, Opts{Package: "test"})
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	msgs[0].IsInput = true
+
+	// the generated code is valid and gofmt-ed Go
+	code, err := goformat.Source([]byte("package test\n\n" + staticValidators(msgs, "test")))
+	require.NoError(t, err)
+	assert.NotContains(t, string(code), "else {\n\t}")
+
+	exp, err := os.ReadFile("testdata/static_validator.go.txt")
+	require.NoError(t, err)
+	assert.Equal(t, string(exp), string(code))
+}
+
+func Test_modelConverters(t *testing.T) {
+	p := loadPluginFromRequestBin(t, "testdata/code_generator_request.pb.bin")
+	_, err := GetMessagesDescriptions(p, Opts{Package: "e2e"})
+	require.NoError(t, err)
+
+	opts := Opts{Package: "e2e", ModelPackage: "modelpb"}
+	basic := messageDescriptions["e2e.Basic"]
+	require.NotNil(t, basic)
+	code := modelConverters(basic, opts)
+	assert.Contains(t, code, "func BasicToModel(pb *e2e.Basic) (*Basic, error) {")
+	assert.Contains(t, code, "func BasicFromModel(m *Basic) (*e2e.Basic, error) {")
+	assert.Contains(t, code, "m.Created = x.AsTime()")
+	assert.Contains(t, code, "pb.Created = timestamppb.New(m.Created)")
+	// the oneof members keep the presence of the zero value
+	assert.Contains(t, code, "if x, ok := pb.B.(*e2e.Basic_Str); ok {\nv := x.Str\nm.Str = &v\n}")
+	assert.Contains(t, code, "if m.Str != nil {\npb.B = &e2e.Basic_Str{Str: *m.Str}\n}")
+
+	duration := &FieldMeta{Type: "struct", StructName: "google.protobuf.Duration"}
+	assert.Equal(t, &modelConversion{expr: "api.DurationModel(x)", err: true}, messageToModel(duration))
+	cond, conv := messageFromModel(duration, "m.Timeout", opts)
+	assert.Equal(t, "m.Timeout != nil", cond)
+	assert.Equal(t, "durationpb.New(*m.Timeout)", conv.expr)
+
+	assert.Empty(t, modelConverters(&MessageDescription{FullName: "e2e.Unknown"}, opts))
+}
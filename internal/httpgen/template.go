@@ -12,8 +12,11 @@ import (
 	"github.com/Masterminds/sprig/v3"
 	"github.com/cockroachdb/errors"
 	"github.com/effective-security/porto/xhttp/httperror"
+	"github.com/effective-security/protoc-gen-go/api"
+	"github.com/effective-security/protoc-gen-go/internal/httprule"
 	"github.com/effective-security/xlog"
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
 )
 
 var logger = xlog.NewPackageLogger("github.com/effective-security/protoc-gen-go", "go-http")
@@ -31,6 +34,9 @@ type Options struct {
 	// {{.Prefix}}{{.Message.GoName}}
 	// If not provided, the the package name of the process file will be used.
 	Prefix string
+	// Validate specifies to validate requests before calling the service,
+	// it can be overridden by es.api.validate_requests service option.
+	Validate bool
 }
 
 // This function is called with a param which contains the entire definition of a method.
@@ -63,10 +69,16 @@ func ApplyTemplate(w io.Writer, f *protogen.File, opts Options) error {
 	return err
 }
 
-func applyServices(w io.Writer, svcs []*protogen.Service, opts Options) error {
+func applyServices(w io.Writer, svcs []*protogen.Service, defaultOpts Options) error {
 	for _, svc := range svcs {
 		logger.Infof("Processing %s", svc.GoName)
 
+		opts := defaultOpts
+		svcOpts := svc.Desc.Options()
+		if proto.HasExtension(svcOpts, api.E_ValidateRequests) {
+			opts.Validate = proto.GetExtension(svcOpts, api.E_ValidateRequests).(bool)
+		}
+
 		if err := serviceTemplate.Execute(w, tplService{
 			Service:    svc,
 			Options:    opts,
@@ -76,8 +88,8 @@ func applyServices(w io.Writer, svcs []*protogen.Service, opts Options) error {
 		}
 
 		for _, met := range svc.Methods {
-			if met.Desc.IsStreamingClient() || met.Desc.IsStreamingServer() {
-				// skip streaming methods
+			if met.Desc.IsStreamingClient() {
+				// skip client and bidi streaming methods
 				continue
 			}
 			if err := methodTemplate.Execute(w, tplMethod{
@@ -94,6 +106,44 @@ func applyServices(w io.Writer, svcs []*protogen.Service, opts Options) error {
 			return errors.Wrapf(err, "failed to execute template: %s", svc.GoName)
 		}
 
+		var methods, routes []tplRoute
+		for _, met := range svc.Methods {
+			if met.Desc.IsStreamingClient() {
+				// client and bidi streaming methods are not supported over HTTP
+				continue
+			}
+			methods = append(methods, tplRoute{Method: met, RPC: true})
+			rules, err := httprule.ForMethod(met)
+			if err != nil {
+				return err
+			}
+			for _, rule := range rules {
+				routes = append(routes, tplRoute{Method: met, Rule: rule})
+			}
+		}
+		if err := routesTemplate.Execute(w, tplRoutes{
+			Service: svc,
+			Options: opts,
+			Routes:  methods,
+			Methods: true,
+		}); err != nil {
+			return errors.Wrapf(err, "failed to execute template: %s", svc.GoName)
+		}
+		if err := routesTemplate.Execute(w, tplRoutes{
+			Service: svc,
+			Options: opts,
+			Routes:  routes,
+		}); err != nil {
+			return errors.Wrapf(err, "failed to execute template: %s", svc.GoName)
+		}
+		if err := registerTemplate.Execute(w, tplService{
+			Service:    svc,
+			Options:    opts,
+			ServerName: svc.GoName + "Server",
+		}); err != nil {
+			return errors.Wrapf(err, "failed to execute template: %s", svc.GoName)
+		}
+
 	}
 
 	return nil
@@ -120,6 +170,23 @@ type tplService struct {
 	ServerName string
 }
 
+type tplRoutes struct {
+	Options
+
+	Service *protogen.Service
+	Routes  []tplRoute
+	// Methods is true for the routes of gRPC method names
+	Methods bool
+}
+
+type tplRoute struct {
+	Method *protogen.Method
+	Rule   httprule.Rule
+	// RPC is true to POST the request to gRPC method name,
+	// otherwise the route is defined by Rule
+	RPC bool
+}
+
 type tplMethod struct {
 	Options
 
@@ -148,6 +215,8 @@ import (
 	"github.com/effective-security/porto/xhttp/httperror"
 	"github.com/effective-security/porto/xhttp/marshal"
 	"github.com/effective-security/porto/pkg/retriable"
+	"github.com/effective-security/protoc-gen-go/api"
+	"google.golang.org/grpc"
 )
 `))
 
@@ -173,10 +242,19 @@ func Get{{.Service.GoName}}HTTPHandler(s {{.PbPackage}}.{{.Service.GoName}}Serve
 		if withAccessCheck != nil {
 			err = withAccessCheck(ctx, req, action)
 			if err != nil {
-				marshal.WriteJSON(w, r, err)
+				api.WriteHTTPError(w, r, err)
 				return
 			}
 		}
+{{- if .Validate }}
+
+		// validate request before calling the service
+		err = api.ValidateMessage(api.WithAllViolations(ctx), req)
+		if err != nil {
+			api.WriteHTTPError(w, r, err)
+			return
+		}
+{{- end }}
 
 		switch action {
 `))
@@ -186,7 +264,15 @@ func Get{{.Service.GoName}}HTTPHandler(s {{.PbPackage}}.{{.Service.GoName}}Serve
 			Parse(`
 
 		case {{.PbPackage}}.{{.Service.GoName}}_{{.Method.GoName}}_FullMethodName:
+{{- if .Method.Desc.IsStreamingServer }}
+			// ServeHTTPStream writes the response and flushes each message
+			api.ServeHTTPStream(ctx, w, r, func(stream grpc.ServerStreamingServer[{{type .Method.Output}}]) error {
+				return s.{{.Method.GoName}}(req.(*{{type .Method.Input}}), stream)
+			})
+			return
+{{- else }}
 			res, err = s.{{.Method.GoName}}(ctx, req.(*{{type .Method.Input}}))
+{{- end }}
 
 `))
 
@@ -196,12 +282,95 @@ func Get{{.Service.GoName}}HTTPHandler(s {{.PbPackage}}.{{.Service.GoName}}Serve
 			err = httperror.Malformed("invalid action: %s", action)
 		}
 		if err != nil {
-			marshal.WriteJSON(w, r, err)
+			api.WriteHTTPError(w, r, err)
 			return
 		}
 
 		marshal.WriteJSON(w, r, res)
 	}
 }
+`))
+
+	routesTemplate = template.Must(template.New("routes").
+			Funcs(tempFuncs()).
+			Parse(`
+{{- if .Methods }}
+// Get{{.Service.GoName}}HTTPMethods returns routes for {{.Service.GoName}} service,
+// where each method is handled by POST to its gRPC method name
+func Get{{.Service.GoName}}HTTPMethods(s {{.PbPackage}}.{{.Service.GoName}}Server, withAccessCheck {{.PbPackage}}.CheckAccessFunc) []api.HTTPRoute {
+{{- else }}
+// Get{{.Service.GoName}}HTTPRoutes returns REST routes for {{.Service.GoName}} service,
+// defined by google.api.http options
+func Get{{.Service.GoName}}HTTPRoutes(s {{.PbPackage}}.{{.Service.GoName}}Server, withAccessCheck {{.PbPackage}}.CheckAccessFunc) []api.HTTPRoute {
+{{- end }}
+	return []api.HTTPRoute{
+{{- range .Routes }}
+{{- $method := printf "%s.%s_%s_FullMethodName" $.PbPackage $.Service.GoName .Method.GoName }}
+		{
+{{- if .RPC }}
+			Method:         http.MethodPost,
+			Path:           {{$method}},
+{{- else }}
+			Method:         "{{.Rule.Method}}",
+			Path:           "{{.Rule.Path}}",
+{{- end }}
+			FullMethodName: {{$method}},
+			Handle: func(w http.ResponseWriter, r *http.Request, p restserver.Params) {
+				ctx := correlation.WithMetaFromRequest(r)
+				req := new({{type .Method.Input}})
+{{- if .RPC }}
+				if err := marshal.DecodeBody(w, r, req); err != nil {
+					// DecodeBody already writes error response
+					return
+				}
+				var err error
+				if withAccessCheck != nil {
+					err = withAccessCheck(ctx, req, {{$method}})
+				}
+{{- else }}
+				err := api.BindHTTPRequest(ctx, r, p, req, "{{.Rule.Body}}"{{range .Rule.PathParams}}, "{{.}}"{{end}})
+				if err == nil && withAccessCheck != nil {
+					err = withAccessCheck(ctx, req, {{$method}})
+				}
+{{- end }}
+{{- if $.Validate }}
+				if err == nil {
+					// validate request before calling the service
+					err = api.ValidateMessage(api.WithAllViolations(ctx), req)
+				}
+{{- end }}
+				if err != nil {
+					api.WriteHTTPError(w, r, err)
+					return
+				}
+{{- if .Method.Desc.IsStreamingServer }}
+				api.ServeHTTPStream(ctx, w, r, func(stream grpc.ServerStreamingServer[{{type .Method.Output}}]) error {
+					return s.{{.Method.GoName}}(req, stream)
+				})
+{{- else }}
+				res, err := s.{{.Method.GoName}}(ctx, req)
+				if err != nil {
+					api.WriteHTTPError(w, r, err)
+					return
+				}
+				marshal.WriteJSON(w, r, res)
+{{- end }}
+			},
+		},
+{{- end }}
+	}
+}
+`))
+
+	registerTemplate = template.Must(template.New("register").
+				Funcs(tempFuncs()).
+				Parse(`
+// Register{{.Service.GoName}}Routes registers a handler per method of {{.Service.GoName}} service:
+// POST to the gRPC method name, and REST routes defined by google.api.http options.
+// The middleware is applied to each route, in the provided order.
+func Register{{.Service.GoName}}Routes(router restserver.Router, s {{.PbPackage}}.{{.ServerName}}, withAccessCheck {{.PbPackage}}.CheckAccessFunc, middleware ...api.RouteMiddleware) {
+	api.RegisterRoutes(router, Get{{.Service.GoName}}HTTPMethods(s, withAccessCheck), middleware...)
+	api.RegisterRoutes(router, Get{{.Service.GoName}}HTTPRoutes(s, withAccessCheck), middleware...)
+}
 `))
 )
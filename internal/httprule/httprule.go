@@ -0,0 +1,156 @@
+// Package httprule parses google.api.http options of the methods.
+package httprule
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+)
+
+// Rule is the REST route parsed from google.api.http option
+type Rule struct {
+	// Method is HTTP method
+	Method string
+	// Template is the path template from google.api.http option
+	Template string
+	// Path is the router path, see ConvertPathTemplate
+	Path string
+	// Body is the body field, or "*"
+	Body string
+	// PathParams is the list of field paths bound from the path variables
+	PathParams []string
+}
+
+// ForMethod returns REST routes for the method, including additional bindings.
+// The error is returned for the rule with unsupported path template,
+// so the method is not generated without its route.
+func ForMethod(met *protogen.Method) ([]Rule, error) {
+	rule, ok := proto.GetExtension(met.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
+	if !ok || rule == nil {
+		return nil, nil
+	}
+
+	var res []Rule
+	for _, r := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+		hr, err := Parse(r)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid google.api.http rule of %s", met.Desc.FullName())
+		}
+		res = append(res, hr)
+	}
+	return res, nil
+}
+
+// Parse returns Rule for google.api.http option,
+// additional bindings are ignored.
+func Parse(rule *annotations.HttpRule) (Rule, error) {
+	var method, tpl string
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		method, tpl = http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		method, tpl = http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		method, tpl = http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		method, tpl = http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		method, tpl = http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		method, tpl = strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+	default:
+		return Rule{}, errors.New("pattern is not specified")
+	}
+
+	path, params, err := ConvertPathTemplate(tpl)
+	if err != nil {
+		return Rule{}, err
+	}
+	return Rule{
+		Method:     method,
+		Template:   tpl,
+		Path:       path,
+		Body:       rule.GetBody(),
+		PathParams: params,
+	}, nil
+}
+
+// ConvertPathTemplate converts google.api.http path template to the router
+// path: {field} and {field=*} are converted to :field,
+// and the trailing {field=**} to *field.
+// The router value of *field starts with "/", that is trimmed by api.BindHTTPRequest.
+// Custom verbs and multi-segment variables, like {name=shelves/*},
+// are not supported by the router.
+func ConvertPathTemplate(tpl string) (string, []string, error) {
+	if !strings.HasPrefix(tpl, "/") {
+		return "", nil, errors.Errorf("path must start with /: %q", tpl)
+	}
+
+	var params []string
+	segments := splitTemplate(tpl[1:])
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, "{") {
+			if strings.Contains(seg, ":") {
+				return "", nil, errors.Errorf("custom verbs are not supported: %q", tpl)
+			}
+			if strings.ContainsAny(seg, "{}*") {
+				return "", nil, errors.Errorf("unsupported path segment %q: %q", seg, tpl)
+			}
+			continue
+		}
+		end := strings.Index(seg, "}")
+		if end == -1 {
+			return "", nil, errors.Errorf("unsupported path variable %q: %q", seg, tpl)
+		}
+		if rest := seg[end+1:]; rest != "" {
+			if strings.HasPrefix(rest, ":") {
+				return "", nil, errors.Errorf("custom verbs are not supported: %q", tpl)
+			}
+			return "", nil, errors.Errorf("unsupported path variable %q: %q", seg, tpl)
+		}
+
+		name, pattern, _ := strings.Cut(seg[1:end], "=")
+		if name == "" {
+			return "", nil, errors.Errorf("empty path variable: %q", tpl)
+		}
+		switch {
+		case pattern == "" || pattern == "*":
+			segments[i] = ":" + name
+		case pattern == "**":
+			if i != len(segments)-1 {
+				return "", nil, errors.Errorf("** must be the last segment: %q", tpl)
+			}
+			segments[i] = "*" + name
+		case strings.Contains(pattern, "/"):
+			return "", nil, errors.Errorf("multi-segment path variable %q is not supported: %q", seg, tpl)
+		default:
+			return "", nil, errors.Errorf("unsupported path variable %q: %q", seg, tpl)
+		}
+		params = append(params, name)
+	}
+	return "/" + strings.Join(segments, "/"), params, nil
+}
+
+// splitTemplate splits the path template by "/" outside of the variables
+func splitTemplate(path string) []string {
+	var res []string
+	depth, start := 0, 0
+	for i, c := range path {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '/':
+			if depth == 0 {
+				res = append(res, path[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(res, path[start:])
+}
@@ -0,0 +1,131 @@
+package httprule_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/effective-security/protoc-gen-go/internal/httprule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestConvertPathTemplate(t *testing.T) {
+	tcases := []struct {
+		tpl    string
+		path   string
+		params []string
+		err    string
+	}{
+		{tpl: "/v1/status", path: "/v1/status"},
+		{tpl: "/v1/items/{id}", path: "/v1/items/:id", params: []string{"id"}},
+		{tpl: "/v1/items/{item.id=*}/sub/{sub}", path: "/v1/items/:item.id/sub/:sub", params: []string{"item.id", "sub"}},
+		{tpl: "/v1/files/{path=**}", path: "/v1/files/*path", params: []string{"path"}},
+		{tpl: "v1/items", err: `path must start with /: "v1/items"`},
+		{tpl: "/v1/items/{id}:cancel", err: `custom verbs are not supported: "/v1/items/{id}:cancel"`},
+		{tpl: "/v1/items:batch", err: `custom verbs are not supported: "/v1/items:batch"`},
+		{tpl: "/v1/{name=shelves/*}", err: `multi-segment path variable "{name=shelves/*}" is not supported: "/v1/{name=shelves/*}"`},
+		{tpl: "/v1/{name=shelves/*/books/*}", err: `multi-segment path variable "{name=shelves/*/books/*}" is not supported: "/v1/{name=shelves/*/books/*}"`},
+		{tpl: "/v1/{name=shelves/*}:move", err: `custom verbs are not supported: "/v1/{name=shelves/*}:move"`},
+		{tpl: "/v1/{id}x", err: `unsupported path variable "{id}x": "/v1/{id}x"`},
+		{tpl: "/v1/{id=a*}", err: `unsupported path variable "{id=a*}": "/v1/{id=a*}"`},
+		{tpl: "/v1/{id", err: `unsupported path variable "{id": "/v1/{id"`},
+		{tpl: "/v1/{path=**}/items", err: `** must be the last segment: "/v1/{path=**}/items"`},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.tpl, func(t *testing.T) {
+			path, params, err := httprule.ConvertPathTemplate(tc.tpl)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.path, path)
+			assert.Equal(t, tc.params, params)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	r, err := httprule.Parse(&annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Patch{Patch: "/v1/items/{id}"},
+		Body:    "item",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, httprule.Rule{Method: "PATCH", Template: "/v1/items/{id}", Path: "/v1/items/:id", Body: "item", PathParams: []string{"id"}}, r)
+
+	r, err = httprule.Parse(&annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Kind: "head", Path: "/v1/items"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "HEAD", r.Method)
+
+	_, err = httprule.Parse(&annotations.HttpRule{})
+	assert.EqualError(t, err, "pattern is not specified")
+}
+
+// testMethods returns the methods of the service with google.api.http rules
+func testMethods(t *testing.T, rules ...*annotations.HttpRule) []*protogen.Method {
+	svc := &descriptorpb.ServiceDescriptorProto{Name: proto.String("Test")}
+	for i, rule := range rules {
+		opts := &descriptorpb.MethodOptions{}
+		proto.SetExtension(opts, annotations.E_Http, rule)
+		svc.Method = append(svc.Method, &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(fmt.Sprintf("Method%d", i)),
+			InputType:  proto.String(".test.Request"),
+			OutputType: proto.String(".test.Request"),
+			Options:    opts,
+		})
+	}
+	gp, err := protogen.Options{}.New(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"test.proto"},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{{
+			Name:        proto.String("test.proto"),
+			Package:     proto.String("test"),
+			Syntax:      proto.String("proto3"),
+			Options:     &descriptorpb.FileOptions{GoPackage: proto.String("example.com/test;test")},
+			MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("Request")}},
+			Service:     []*descriptorpb.ServiceDescriptorProto{svc},
+		}},
+	})
+	require.NoError(t, err)
+	return gp.Files[0].Services[0].Methods
+}
+
+func TestForMethod(t *testing.T) {
+	mets := testMethods(t,
+		&annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Get{Get: "/v1/items/{id}"},
+			AdditionalBindings: []*annotations.HttpRule{
+				{Pattern: &annotations.HttpRule_Get{Get: "/v1/items/{id}/details"}},
+			},
+		},
+		&annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/{name=shelves/*}"}},
+		&annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: "/v1/items/{id}:cancel"}},
+		&annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Get{Get: "/v1/items"},
+			AdditionalBindings: []*annotations.HttpRule{
+				{Pattern: &annotations.HttpRule_Get{Get: "/v1/{name=shelves/*/books/*}"}},
+			},
+		},
+	)
+
+	rules, err := httprule.ForMethod(mets[0])
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "/v1/items/:id", rules[0].Path)
+	assert.Equal(t, "/v1/items/:id/details", rules[1].Path)
+
+	// the unsupported rules fail the generation with the method and the template
+	_, err = httprule.ForMethod(mets[1])
+	assert.EqualError(t, err, `invalid google.api.http rule of test.Test.Method1: multi-segment path variable "{name=shelves/*}" is not supported: "/v1/{name=shelves/*}"`)
+	_, err = httprule.ForMethod(mets[2])
+	assert.EqualError(t, err, `invalid google.api.http rule of test.Test.Method2: custom verbs are not supported: "/v1/items/{id}:cancel"`)
+	_, err = httprule.ForMethod(mets[3])
+	assert.EqualError(t, err, `invalid google.api.http rule of test.Test.Method3: multi-segment path variable "{name=shelves/*/books/*}" is not supported: "/v1/{name=shelves/*/books/*}"`)
+}
@@ -175,7 +175,21 @@ func (m *{{.StructName}}) next() proto.Message {
 			Funcs(tempFuncs()).
 			Parse(`
 {{ .Method.Comments.Leading -}}			
-{{- if .Method.Desc.IsStreamingServer }}
+{{- if and .Method.Desc.IsStreamingClient .Method.Desc.IsStreamingServer }}
+func (m *{{.StructName}}) {{.Method.GoName}}(srv grpc.BidiStreamingServer[{{type .Method.Input}}, {{type .Method.Output}}]) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	return nil
+}
+{{- else if .Method.Desc.IsStreamingClient }}
+func (m *{{.StructName}}) {{.Method.GoName}}(srv grpc.ClientStreamingServer[{{type .Method.Input}}, {{type .Method.Output}}]) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	return srv.SendAndClose(m.next().(*{{type .Method.Output}}))
+}
+{{- else if .Method.Desc.IsStreamingServer }}
 func (m *{{.StructName}}) {{.Method.GoName}}(req *{{type .Method.Input}}, srv grpc.ServerStreamingServer[{{type .Method.Output}}]) error {
 	if m.Err != nil {
 		return m.Err
@@ -0,0 +1,16 @@
+// Package plugin provides the settings shared by the protoc plugins.
+package plugin
+
+import (
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// ConfigureFeatures declares proto3 optional fields and editions support of the plugin,
+// without it protoc rejects the files with the optional fields or editions.
+func ConfigureFeatures(gp *protogen.Plugin) {
+	gp.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL | pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS)
+	gp.SupportedEditionsMinimum = descriptorpb.Edition_EDITION_PROTO2
+	gp.SupportedEditionsMaximum = descriptorpb.Edition_EDITION_2023
+}
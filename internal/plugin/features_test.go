@@ -0,0 +1,25 @@
+package plugin_test
+
+import (
+	"testing"
+
+	"github.com/effective-security/protoc-gen-go/internal/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestConfigureFeatures(t *testing.T) {
+	gp, err := protogen.Options{}.New(&pluginpb.CodeGeneratorRequest{})
+	require.NoError(t, err)
+
+	plugin.ConfigureFeatures(gp)
+
+	res := gp.Response()
+	assert.Empty(t, res.GetError())
+	assert.Equal(t, uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL|pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS), res.GetSupportedFeatures())
+	assert.Equal(t, int32(descriptorpb.Edition_EDITION_PROTO2), res.GetMinimumEdition())
+	assert.Equal(t, int32(descriptorpb.Edition_EDITION_2023), res.GetMaximumEdition())
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/Masterminds/sprig/v3"
 	"github.com/cockroachdb/errors"
 	"github.com/effective-security/porto/xhttp/httperror"
+	"github.com/effective-security/protoc-gen-go/internal/httprule"
 	"github.com/effective-security/xlog"
 	"google.golang.org/protobuf/compiler/protogen"
 )
@@ -76,6 +77,14 @@ func applyServices(w io.Writer, svcs []*protogen.Service, opts Options) error {
 		}
 
 		for _, met := range svc.Methods {
+			rules, err := httprule.ForMethod(met)
+			if err != nil {
+				return err
+			}
+			var rule *httprule.Rule
+			if len(rules) > 0 {
+				rule = &rules[0]
+			}
 			if err := methodTemplate.Execute(w, tplMethod{
 				Service:          svc,
 				Method:           met,
@@ -83,6 +92,7 @@ func applyServices(w io.Writer, svcs []*protogen.Service, opts Options) error {
 				ProxyStructName:  proxyName,
 				ClientStructName: clientName,
 				Namespace:        string(svc.Desc.FullName()),
+				Rule:             rule,
 			}); err != nil {
 				return errors.Wrapf(err, "failed to execute template: %s", met.GoName)
 			}
@@ -124,6 +134,8 @@ type tplMethod struct {
 	ProxyStructName  string
 	ClientStructName string
 	Namespace        string
+	// Rule is the primary google.api.http rule, if provided
+	Rule *httprule.Rule
 }
 
 var (
@@ -166,6 +178,10 @@ type post{{.ClientStructName}} struct {
 	client   retriable.PostRequester
 }
 
+type rest{{.ClientStructName}} struct {
+	client api.HTTPRequester
+}
+
 // {{.Service.GoName}}ServerToClient returns {{.Prefix}}{{.ClientName}}
 func {{.Service.GoName}}ServerToClient(srv {{.Prefix}}{{.ServerName}}) {{.Prefix}}{{.ClientName}} {
 	return &{{.ProxyStructName}}{srv}
@@ -186,38 +202,117 @@ func New{{.ClientName}}FromProxy(proxy {{.Prefix}}{{.ClientName}}) {{.Prefix}}{{
 	}
 }
 
-// New{{.ClientName}}FromProxy returns instance of {{.ClientName}}
+// NewHTTP{{.ClientName}} returns instance of {{.ClientName}} over HTTP,
+// server-streaming methods require the client to implement api.StreamRequester.
 func NewHTTP{{.ClientName}}(client retriable.PostRequester) {{.Prefix}}{{.ServerName}} {
 	return &post{{.ClientStructName}}{
 		client: client,
 	}
 }
 
+// NewREST{{.ClientName}} returns instance of {{.ClientName}} over HTTP,
+// methods with google.api.http option are called with the REST route,
+// and other methods are posted to the gRPC method name.
+// Server-streaming methods require the client to implement api.StreamRequester.
+func NewREST{{.ClientName}}(client api.HTTPRequester) {{.Prefix}}{{.ServerName}} {
+	return &rest{{.ClientStructName}}{
+		client: client,
+	}
+}
+
 `))
 	methodTemplate = template.Must(template.New("method").
 			Funcs(tempFuncs()).
 			Parse(`
 
-{{- if .Method.Desc.IsStreamingServer }}
+{{- if and .Method.Desc.IsStreamingClient .Method.Desc.IsStreamingServer }}
+{{- .Method.Comments.Leading -}}
+// bidi streaming proxy Client to Server
+func (s *{{.ProxyStructName}}) {{.Method.GoName}}(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[{{type .Method.Input}}, {{type .Method.Output}}], error) {
+	// add correlation ID to outgoing RPC calls
+	ctx = correlation.WithMetaFromContext(ctx)
+	md := api.NewStreamMetadata({{.Prefix}}{{.Service.GoName}}_{{.Method.GoName}}_FullMethodName)
+	reqs := make(chan *{{type .Method.Input}}, 8)
+	msgs := make(chan *{{type .Method.Output}}, 8)
+	done := make(chan struct{})
+	clientStream := &api.ProxyBidiStreamingClient[{{type .Method.Input}}, {{type .Method.Output}}]{
+		Reqs: reqs,
+		Msgs: msgs,
+		Done: done,
+		Ctx:  ctx,
+		MD:   md,
+	}
+
+	go func() {
+		// run the real server handler in‑proc
+		serverStream := &api.BidiStreamServerAdapter[{{type .Method.Input}}, {{type .Method.Output}}]{
+			DummyServerStream: api.DummyServerStream{Ctx: md.NewContext(ctx), MD: md},
+			Reqs:              reqs,
+			Msgs:              msgs,
+		}
+		err := s.srv.{{.Method.GoName}}(serverStream)
+		clientStream.ErrOnce.Do(func() { clientStream.Err = err })
+		md.Finish()
+		close(done)
+		close(msgs)
+	}()
+
+	return clientStream, nil
+}
+{{- else if .Method.Desc.IsStreamingClient }}
+{{- .Method.Comments.Leading -}}
+// client streaming proxy Client to Server
+func (s *{{.ProxyStructName}}) {{.Method.GoName}}(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[{{type .Method.Input}}, {{type .Method.Output}}], error) {
+	// add correlation ID to outgoing RPC calls
+	ctx = correlation.WithMetaFromContext(ctx)
+	md := api.NewStreamMetadata({{.Prefix}}{{.Service.GoName}}_{{.Method.GoName}}_FullMethodName)
+	reqs := make(chan *{{type .Method.Input}}, 8)
+	done := make(chan struct{})
+	clientStream := &api.ProxyClientStreamingClient[{{type .Method.Input}}, {{type .Method.Output}}]{
+		Reqs: reqs,
+		Done: done,
+		Ctx:  ctx,
+		MD:   md,
+	}
+
+	go func() {
+		// run the real server handler in‑proc
+		serverStream := &api.ClientStreamServerAdapter[{{type .Method.Input}}, {{type .Method.Output}}]{
+			DummyServerStream: api.DummyServerStream{Ctx: md.NewContext(ctx), MD: md},
+			Reqs:              reqs,
+		}
+		err := s.srv.{{.Method.GoName}}(serverStream)
+		clientStream.Res = serverStream.Res
+		clientStream.Err = err
+		md.Finish()
+		close(done)
+	}()
+
+	return clientStream, nil
+}
+{{- else if .Method.Desc.IsStreamingServer }}
 {{- .Method.Comments.Leading -}}
 // streaming proxy Client to Server
 func (s *{{.ProxyStructName}}) {{.Method.GoName}}(ctx context.Context, req *{{type .Method.Input}}, opts ...grpc.CallOption) (grpc.ServerStreamingClient[{{type .Method.Output}}], error) {
 	// add correlation ID to outgoing RPC calls
 	ctx = correlation.WithMetaFromContext(ctx)
+	md := api.NewStreamMetadata({{.Prefix}}{{.Service.GoName}}_{{.Method.GoName}}_FullMethodName)
 	msgs := make(chan *{{type .Method.Output}}, 8)
     clientStream := &api.ProxyClientStream[{{type .Method.Output}}]{
         Msgs: msgs,
         Ctx:  ctx,
+        MD:   md,
     }
 
     go func() {
         // run the real server handler in‑proc
         serverStream := &api.ServerStreamAdapter[{{type .Method.Output}}]{
-            DummyServerStream: api.DummyServerStream{Ctx: ctx},
+            DummyServerStream: api.DummyServerStream{Ctx: md.NewContext(ctx), MD: md},
             Msgs:              msgs,
         }
         err := s.srv.{{.Method.GoName}}(req, serverStream)
         clientStream.ErrOnce.Do(func() { clientStream.Err = err })
+        md.Finish()
         close(msgs)
     }()
 
@@ -228,7 +323,10 @@ func (s *{{.ProxyStructName}}) {{.Method.GoName}}(ctx context.Context, req *{{ty
 func (s *{{.ProxyStructName}}) {{.Method.GoName}}(ctx context.Context, req *{{type .Method.Input}}, opts ...grpc.CallOption) (*{{type .Method.Output}}, error) {
 	// add correlation ID to outgoing RPC calls
 	ctx = correlation.WithMetaFromContext(ctx)
-	res, err := s.srv.{{.Method.GoName}}(ctx, req)
+	md := api.NewStreamMetadata({{.Prefix}}{{.Service.GoName}}_{{.Method.GoName}}_FullMethodName)
+	res, err := s.srv.{{.Method.GoName}}(md.NewContext(ctx), req)
+	// return header and trailer set by the handler to grpc.Header and grpc.Trailer options
+	md.ApplyCallOptions(opts...)
 	if err != nil {
 		return nil, httperror.NewFromPb(err)
 	}
@@ -236,7 +334,92 @@ func (s *{{.ProxyStructName}}) {{.Method.GoName}}(ctx context.Context, req *{{ty
 }
 {{- end }}
 
-{{- if .Method.Desc.IsStreamingServer }}
+{{- if and .Method.Desc.IsStreamingClient .Method.Desc.IsStreamingServer }}
+{{ .Method.Comments.Leading -}}
+ // bidi streaming proxy Server to Client
+func (s *{{.ClientStructName}}) {{.Method.GoName}}(srv grpc.BidiStreamingServer[{{type .Method.Input}}, {{type .Method.Output}}]) error {
+	// add correlation ID to outgoing RPC calls,
+	// the remote stream is cancelled when the handler returns
+	ctx, cancel := context.WithCancel(correlation.WithMetaFromContext(srv.Context()))
+	defer cancel()
+	// 1) Dial out through the real gRPC client:
+	stream, err := s.remote.{{.Method.GoName}}(ctx, s.callOpts...)
+	if err != nil {
+		return err
+	}
+
+	// 2) Pump requests to the remote stream:
+	go func() {
+		for {
+			req, err := srv.Recv()
+			if err != nil {
+				_ = stream.CloseSend()
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+			if err := stream.Send(req); err != nil {
+				// the status is returned by stream.Recv
+				return
+			}
+		}
+	}()
+
+	// 3) Pump responses back to the server stream:
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return httperror.NewFromPb(err)
+		}
+		if err := srv.Send(msg); err != nil {
+			return err
+		}
+	}
+}
+{{- else if .Method.Desc.IsStreamingClient }}
+{{ .Method.Comments.Leading -}}
+ // client streaming proxy Server to Client
+func (s *{{.ClientStructName}}) {{.Method.GoName}}(srv grpc.ClientStreamingServer[{{type .Method.Input}}, {{type .Method.Output}}]) error {
+	// add correlation ID to outgoing RPC calls
+	ctx := correlation.WithMetaFromContext(srv.Context())
+	// 1) Dial out through the real gRPC client:
+	stream, err := s.remote.{{.Method.GoName}}(ctx, s.callOpts...)
+	if err != nil {
+		return err
+	}
+
+	// 2) Pump requests to the remote stream:
+	for {
+		req, err := srv.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(req); err != nil {
+			if err == io.EOF {
+				// the status is returned by CloseAndRecv
+				break
+			}
+			return err
+		}
+	}
+
+	// 3) Return the response to the server stream:
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return httperror.NewFromPb(err)
+	}
+	return srv.SendAndClose(res)
+}
+{{- else if .Method.Desc.IsStreamingServer }}
 {{ .Method.Comments.Leading -}}
  // streaming proxy Server to Client
 func (s *{{.ClientStructName}}) {{.Method.GoName}}(req *{{type .Method.Input}}, srv grpc.ServerStreamingServer[{{type .Method.Output}}]) error {
@@ -253,7 +436,7 @@ func (s *{{.ClientStructName}}) {{.Method.GoName}}(req *{{type .Method.Input}},
             if err == io.EOF {
                 return nil
             }
-            return err
+            return httperror.NewFromPb(err)
         }
         if err := srv.Send(msg); err != nil {
             return err
@@ -273,12 +456,28 @@ func (s *{{.ClientStructName}}) {{.Method.GoName}}(ctx context.Context, req *{{t
 }
 {{- end }}
 
-{{- if .Method.Desc.IsStreamingServer }}
+{{- if and .Method.Desc.IsStreamingClient .Method.Desc.IsStreamingServer }}
 {{ .Method.Comments.Leading -}}
- func (s *post{{.ClientStructName}}) {{.Method.GoName}}(req *{{type .Method.Input}}, res grpc.ServerStreamingServer[{{type .Method.Output}}]) error {
+ func (s *post{{.ClientStructName}}) {{.Method.GoName}}(srv grpc.BidiStreamingServer[{{type .Method.Input}}, {{type .Method.Output}}]) error {
+ 	// not supported
+	return httperror.NewGrpc(codes.Unimplemented, "streaming not supported")
+}
+{{- else if .Method.Desc.IsStreamingClient }}
+{{ .Method.Comments.Leading -}}
+ func (s *post{{.ClientStructName}}) {{.Method.GoName}}(srv grpc.ClientStreamingServer[{{type .Method.Input}}, {{type .Method.Output}}]) error {
  	// not supported
 	return httperror.NewGrpc(codes.Unimplemented, "streaming not supported")
 }
+{{- else if .Method.Desc.IsStreamingServer }}
+{{ .Method.Comments.Leading -}}
+ func (s *post{{.ClientStructName}}) {{.Method.GoName}}(req *{{type .Method.Input}}, res grpc.ServerStreamingServer[{{type .Method.Output}}]) error {
+	client, ok := s.client.(api.StreamRequester)
+	if !ok {
+		return httperror.NewGrpc(codes.Unimplemented, "streaming not supported")
+	}
+	path := {{.Namespace}}_{{.Method.GoName}}_FullMethodName
+	return api.PostStream(res.Context(), client, path, req, res.Send)
+}
 {{- else }}
 {{ .Method.Comments.Leading -}}
 func (s *post{{.ClientStructName}}) {{.Method.GoName}}(ctx context.Context, req *{{type .Method.Input}}) (*{{type .Method.Output}}, error) {
@@ -292,5 +491,43 @@ func (s *post{{.ClientStructName}}) {{.Method.GoName}}(ctx context.Context, req
 }
 {{- end }}
 
+{{- if .Method.Desc.IsStreamingClient }}
+{{ .Method.Comments.Leading -}}
+ func (s *rest{{.ClientStructName}}) {{.Method.GoName}}(srv grpc.{{if .Method.Desc.IsStreamingServer}}Bidi{{else}}Client{{end}}StreamingServer[{{type .Method.Input}}, {{type .Method.Output}}]) error {
+ 	// not supported
+	return httperror.NewGrpc(codes.Unimplemented, "streaming not supported")
+}
+{{- else if .Method.Desc.IsStreamingServer }}
+{{ .Method.Comments.Leading -}}
+ func (s *rest{{.ClientStructName}}) {{.Method.GoName}}(req *{{type .Method.Input}}, res grpc.ServerStreamingServer[{{type .Method.Output}}]) error {
+	client, ok := s.client.(api.StreamRequester)
+	if !ok {
+		return httperror.NewGrpc(codes.Unimplemented, "streaming not supported")
+	}
+	path := {{.Prefix}}{{.Service.GoName}}_{{.Method.GoName}}_FullMethodName
+	return api.PostStream(res.Context(), client, path, req, res.Send)
+}
+{{- else }}
+{{ .Method.Comments.Leading -}}
+func (s *rest{{.ClientStructName}}) {{.Method.GoName}}(ctx context.Context, req *{{type .Method.Input}}) (*{{type .Method.Output}}, error) {
+{{- if .Rule }}
+	path, body, err := api.HTTPRequestParams(req, "{{.Rule.Template}}", "{{.Rule.Body}}")
+	if err != nil {
+		return nil, err
+	}
+	var res {{type .Method.Output}}
+	_, _, err = s.client.Request(ctx, "{{.Rule.Method}}", path, body, &res)
+{{- else }}
+	var res {{type .Method.Output}}
+	path := {{.Prefix}}{{.Service.GoName}}_{{.Method.GoName}}_FullMethodName
+	_, _, err := s.client.Request(ctx, http.MethodPost, path, req, &res)
+{{- end }}
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+{{- end }}
+
 `))
 )